@@ -0,0 +1,82 @@
+// Package botapi reads calls of the Telegram Bot API, for the fakes of it in
+// the tests and the terminal client.
+package botapi
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// Button is an inline button of a message.
+type Button struct {
+	Text string
+	Data string
+}
+
+// Read the parameters of a call, sent as JSON or as a form. Values which are
+// not strings or numbers are left as JSON.
+func ReadParams(r *http.Request) (map[string]string, error) {
+	params := map[string]string{}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		values := map[string]interface{}{}
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		err := decoder.Decode(&values)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		for key, value := range values {
+			switch value := value.(type) {
+			case string:
+				params[key] = value
+			case json.Number:
+				params[key] = value.String()
+			default:
+				data, _ := json.Marshal(value)
+				params[key] = string(data)
+			}
+		}
+	case "multipart/form-data":
+		err := r.ParseMultipartForm(10 << 20)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range r.MultipartForm.Value {
+			params[key] = values[0]
+		}
+	default:
+		err := r.ParseForm()
+		if err != nil {
+			return nil, err
+		}
+		for key := range r.Form {
+			params[key] = r.Form.Get(key)
+		}
+	}
+	return params, nil
+}
+
+// Inline buttons of reply_markup, in order.
+func ReadButtons(markup string) []Button {
+	keyboard := struct {
+		InlineKeyboard [][]struct {
+			Text         string `json:"text"`
+			CallbackData string `json:"callback_data"`
+		} `json:"inline_keyboard"`
+	}{}
+	json.Unmarshal([]byte(markup), &keyboard)
+	buttons := make([]Button, 0)
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			buttons = append(buttons, Button{
+				Text: button.Text,
+				Data: button.CallbackData,
+			})
+		}
+	}
+	return buttons
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/magicae/texas-holdem-bot/texas"
+)
+
+// Strength of the hand of a seat from 0 to 1. Before the flop it comes from
+// the hole cards, after it from the best hand.
+func handStrength(game *texas.Texas, seat int) float64 {
+	round := game.Round
+	strength := 0.0
+	if top := round.TopCards[seat]; top != nil {
+		// One pair is 0.4, three of a kind 0.8, a straight or better 1.
+		strength = float64(top.GetRank()+1) / 5
+	} else {
+		hole := round.PlayerCards[seat]
+		strength = float64(hole[0].Rank+hole[1].Rank-4) / 24 * 0.6
+		if hole[0].Rank == hole[1].Rank {
+			strength += 0.35
+		}
+		if hole[0].Suit == hole[1].Suit {
+			strength += 0.05
+		}
+	}
+	if strength > 1 {
+		strength = 1
+	}
+	return strength
+}
+
+// Choose the command of an AI seat to act.
+func decide(game *texas.Texas, seat int) string {
+	round := game.Round
+	toCall := game.MaxBet() - round.StageBets[seat]
+	chip := game.Players[seat].Chip
+	// Play a little unpredictably.
+	strength := handStrength(game, seat) + rand.Float64()*0.2 - 0.1
+	switch {
+	case strength > 0.75:
+		raise := round.Pot / 2 / game.BigBlind * game.BigBlind
+		if raise < game.BigBlind {
+			raise = game.BigBlind
+		}
+		if toCall+raise >= chip {
+			return "/allin"
+		}
+		return fmt.Sprint("/raise ", raise)
+	case toCall == 0:
+		return "/check"
+	case toCall >= chip:
+		if strength > 0.6 {
+			return "/allin"
+		}
+		return "/fold"
+	case strength > 0.35 || toCall <= game.BigBlind && strength > 0.2:
+		return "/call"
+	}
+	return "/fold"
+}
@@ -0,0 +1,175 @@
+// Command texas-cli plays a table in the terminal against AI seats, without
+// Telegram and Redis.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+	"github.com/magicae/texas-holdem-bot/texas"
+)
+
+const (
+	botID       = 1000
+	botUsername = "TexasCLIBot"
+	// The table is a group and the human has the private chat of the ID.
+	groupID = -1
+	humanID = 1
+)
+
+var (
+	seats = flag.Int("ai", 3, "Number of AI seats, 1 to 9")
+	money = flag.Int64("money", 10000, "Money in every wallet")
+	name  = flag.String("name", "", "Your name")
+	cards = flag.String("cards", texas.CardsEmoji,
+		"Card style: emoji, ascii, four-color or spoken")
+	lang  = flag.String("lang", "en", "Language")
+	delay = flag.Duration("delay", 500*time.Millisecond,
+		"How long AI seats think")
+)
+
+// Players at the table by user ID.
+var users = map[int]*User{}
+
+var messageID = 0
+
+// Send a command to the table from the user.
+func send(e *Bot, user *User, text string) {
+	messageID++
+	texas.HandleMessage(e, &Message{
+		MessageID: messageID,
+		From:      user,
+		Chat:      &Chat{ID: groupID, Type: "group", Title: "Terminal"},
+		Date:      int(time.Now().Unix()),
+		Text:      text,
+	})
+}
+
+// State of the hand to find out whether a command did anything.
+func handState(game *texas.Texas) string {
+	if game == nil || game.Round == nil {
+		return ""
+	}
+	round := game.Round
+	return fmt.Sprint(round.Stage, round.ActorIndex, round.Pot,
+		round.StageBets)
+}
+
+// Let the AI seat act, falling back to checking and folding if the command
+// is refused. Returns false if every command is refused.
+func actAI(e *Bot, game *texas.Texas, seat int) bool {
+	time.Sleep(*delay)
+	user := users[game.Players[seat].UserID]
+	state := handState(game)
+	for _, command := range []string{decide(game, seat), "/check",
+		"/fold"} {
+		send(e, user, command)
+		if handState(texas.GetGame(groupID)) != state {
+			return true
+		}
+	}
+	fmt.Printf("%s can not act.\n\n", user.FirstName)
+	return false
+}
+
+func main() {
+	flag.Parse()
+	rand.Seed(time.Now().UnixNano())
+	if *seats < 1 || *seats > 9 {
+		fmt.Fprintln(os.Stderr, "-ai must be 1 to 9")
+		os.Exit(2)
+	}
+	switch *cards {
+	case texas.CardsEmoji, texas.CardsASCII, texas.CardsFourColor,
+		texas.CardsSpoken:
+	default:
+		fmt.Fprintln(os.Stderr, "-cards must be emoji, ascii, four-color or spoken")
+		os.Exit(2)
+	}
+	config.UpdateBot(func(bot *config.BotConfig) {
+		bot.ID = botID
+		bot.Username = botUsername
+		bot.CardStyle = *cards
+		bot.Language = *lang
+	})
+	store := texas.NewMemoryStore()
+	texas.UseStore(store)
+	// Calls of the Bot API never leave the process.
+	http.DefaultTransport = &apiTransport{
+		out:     os.Stdout,
+		groupID: groupID,
+		humanID: humanID,
+	}
+	e := NewBot("")
+
+	if *name == "" {
+		*name = os.Getenv("USER")
+	}
+	if *name == "" {
+		*name = "Player"
+	}
+	human := &User{
+		ID:        humanID,
+		FirstName: *name,
+		Username:  strings.ToLower(strings.Replace(*name, " ", "", -1)),
+	}
+	users[humanID] = human
+	for i := 1; i <= *seats; i++ {
+		users[humanID+i] = &User{
+			ID:        humanID + i,
+			FirstName: fmt.Sprint("AI ", i),
+			Username:  fmt.Sprint("ai", i),
+		}
+	}
+	for id := range users {
+		store.Register(id, int64(id))
+		store.Credit(id, *money, texas.LedgerEntry{Reason: texas.LedgerGetMoney})
+	}
+	send(e, human, "/new")
+	for i := 1; i <= *seats; i++ {
+		send(e, users[humanID+i], "/join")
+	}
+	fmt.Println("Type commands as in Telegram, like /startgame, /call, " +
+		"/raise 200 or /fold. q quits.")
+	fmt.Println()
+
+	input := bufio.NewScanner(os.Stdin)
+	for {
+		game := texas.GetGame(groupID)
+		if game == nil {
+			break
+		}
+		// AI seats wait while the table is paused, and the human gets the
+		// prompt if they can not act.
+		if game.Round != nil && game.Round.Stage < texas.End && !game.Paused {
+			seat := game.Round.ActorIndex
+			if game.Players[seat].UserID != humanID && actAI(e, game, seat) {
+				continue
+			}
+		}
+		fmt.Print("> ")
+		if !input.Scan() {
+			break
+		}
+		line := strings.TrimSpace(input.Text())
+		if line == "q" || line == "quit" {
+			break
+		}
+		if line != "" {
+			send(e, human, line)
+		}
+	}
+	if texas.GetGame(groupID) != nil {
+		send(e, human, "/leave")
+	}
+	balance, _ := store.Balance(humanID)
+	fmt.Printf("\nYou leave with $%d.\n", balance)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magicae/texas-holdem-bot/botapi"
+)
+
+// apiTransport answers calls of the Bot API in the process, and prints the
+// messages sent to the table and to the human.
+type apiTransport struct {
+	mutex     sync.Mutex
+	out       io.Writer
+	groupID   int64
+	humanID   int64
+	messageID int
+}
+
+// Text of the inline buttons in reply_markup, like "[Check] [Fold]".
+func buttonsText(markup string) string {
+	texts := make([]string, 0)
+	for _, button := range botapi.ReadButtons(markup) {
+		texts = append(texts, "["+button.Text+"]")
+	}
+	return strings.Join(texts, " ")
+}
+
+// Print a message if it is for the table or the human.
+func (t *apiTransport) print(chatID int64, text string, markup string) {
+	prefix := ""
+	switch chatID {
+	case t.groupID:
+	case t.humanID:
+		prefix = "(private) "
+	default:
+		return
+	}
+	fmt.Fprintln(t.out, prefix+strings.Replace(text, "\n", "\n"+prefix, -1))
+	if buttons := buttonsText(markup); buttons != "" {
+		fmt.Fprintln(t.out, prefix+buttons)
+	}
+	fmt.Fprintln(t.out)
+}
+
+// Answer a call and return its result.
+func (t *apiTransport) call(method string, params map[string]string) (
+	interface{}, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+	switch method {
+	case "getMe":
+		return map[string]interface{}{
+			"id":         botID,
+			"is_bot":     true,
+			"first_name": "Texas",
+			"username":   botUsername,
+		}, nil
+	case "getChatAdministrators":
+		return []interface{}{}, nil
+	case "sendMessage":
+		t.print(chatID, params["text"], params["reply_markup"])
+	case "editMessageText":
+		t.print(chatID, params["text"], params["reply_markup"])
+		messageID, _ := strconv.Atoi(params["message_id"])
+		return t.message(chatID, messageID), nil
+	case "sendSticker":
+		t.print(chatID, "[sticker]", "")
+	case "sendPhoto", "sendDocument":
+		t.print(chatID, params["caption"], "")
+	default:
+		return true, nil
+	}
+	t.messageID++
+	return t.message(chatID, t.messageID), nil
+}
+
+// A message sent by the bot.
+func (t *apiTransport) message(chatID int64, messageID int) interface{} {
+	return map[string]interface{}{
+		"message_id": messageID,
+		"date":       time.Now().Unix(),
+		"chat":       map[string]interface{}{"id": chatID},
+		"from": map[string]interface{}{
+			"id":         botID,
+			"is_bot":     true,
+			"first_name": "Texas",
+			"username":   botUsername,
+		},
+	}
+}
+
+func (t *apiTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	response := map[string]interface{}{"ok": true}
+	params, err := botapi.ReadParams(r)
+	if err == nil {
+		response["result"], err = t.call(path.Base(r.URL.Path), params)
+	}
+	if err != nil {
+		response = map[string]interface{}{
+			"ok":          false,
+			"error_code":  http.StatusBadRequest,
+			"description": err.Error(),
+		}
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       r,
+	}, nil
+}
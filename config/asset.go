@@ -1,7 +1,10 @@
 package config
 
-// PokerFileIDs stores FileIDs of all stickers in poker_cards stickers pack.
-var PokerFileIDs = [4][15]string{
+import "sync/atomic"
+
+// defaultPokerFileIDs stores FileIDs of all stickers in poker_cards stickers
+// pack.
+var defaultPokerFileIDs = [4][15]string{
 	// Diamonds
 	[15]string{
 		"", // 0
@@ -76,6 +79,18 @@ var PokerFileIDs = [4][15]string{
 	},
 }
 
+var currentPokerFileIDs atomic.Value
+
+func init() {
+	fileIDs := defaultPokerFileIDs
+	currentPokerFileIDs.Store(&fileIDs)
+}
+
+// PokerFileID returns the FileID of the sticker of a card.
+func PokerFileID(suit int, rank int) string {
+	return currentPokerFileIDs.Load().(*[4][15]string)[suit][rank]
+}
+
 var PokerSuitTexts = [4]string{"♦️", "♥️", "♣️", "♠️"}
 var PokerRankTexts = [15]string{"", "", "2", "3", "4", "5", "6", "7", "8", "9",
 	"10", "J", "Q", "K", "A"}
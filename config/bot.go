@@ -0,0 +1,142 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magicae/telegram-bot"
+)
+
+type BotConfig struct {
+	ID            int
+	Token         string
+	Username      string
+	GetMoneyBase  int64
+	GetMoneyBonus int64
+	InGameButtons []*bot.KeyboardButton
+	OutButtons    []*bot.KeyboardButton
+	// Daily money grows by GetMoneyStreakBonus percent for every day in a
+	// row, up to GetMoneyMaxStreak days. Days start at midnight in Timezone.
+	GetMoneyStreakBonus int64
+	GetMoneyMaxStreak   int64
+	Timezone            string
+	// Empty wallets can get ReliefMoney once every ReliefCooldown.
+	ReliefMoney    int64
+	ReliefCooldown time.Duration
+	// Users can /give up to GiveDailyLimit a day once their accounts are
+	// GiveMinAccountAge old.
+	GiveDailyLimit    int64
+	GiveMinAccountAge time.Duration
+	// Leaderboards are reset every SeasonDays days, 0 to disable.
+	SeasonDays int
+	// Receive updates at WebhookURL instead of long polling. Telegram posts
+	// them to WebhookListen with WebhookSecret. Without WebhookCert and
+	// WebhookKey, it serves plain HTTP behind an HTTPS proxy.
+	WebhookURL    string
+	WebhookListen string
+	WebhookSecret string
+	WebhookCert   string
+	WebhookKey    string
+	// Language of chats which have not chosen one by /lang.
+	Language string
+	// Cards are shown as CardStyle in chats which have not chosen one by
+	// /cards: "image", "sticker", "emoji", "ascii", "four-color" or "spoken".
+	CardStyle string
+	// Table and card images are drawn with CardTheme: "classic", "dark" or
+	// "four-color". Cards are drawn from PNG files in CardArt if it is set,
+	// named by the card like "As.png", "Td.png" and "back.png".
+	CardTheme string
+	CardArt   string
+	// Admins are user IDs allowed to use admin commands like /audit.
+	Admins []int
+	// Blinds of new tables.
+	SmallBlind int64
+	BigBlind   int64
+	// Players buy in with MaxBuyIn, or all their money if they have less.
+	// They need at least MinBuyIn to join.
+	MinBuyIn int64
+	MaxBuyIn int64
+	// How long a /give waits for /confirm.
+	GiveTimeout time.Duration
+	// How long a command waits for its table, and how long a table without
+	// commands keeps its goroutine.
+	TableTimeout time.Duration
+	TableIdle    time.Duration
+	// Serve /metrics and /healthz at MetricsListen, empty to disable.
+	MetricsListen string
+	// Serve the API of web clients at APIListen, empty to disable. Tokens
+	// of /web are signed with APISecret and link to the client at APIURL.
+	// Browsers may call the API from APIOrigin. People without Telegram
+	// can sign up on the web if APISignup is set.
+	APIListen string
+	APISecret string
+	APIURL    string
+	APIOrigin string
+	APISignup bool
+}
+
+// Defaults in the source, which the file and the environment override.
+var defaultBot = BotConfig{
+	// Set the token in the configuration file or TEXAS_TOKEN.
+	Token:         "",
+	GetMoneyBase:  500,
+	GetMoneyBonus: 9500,
+	// +10% every day in a row, up to +60% on the 7th day.
+	GetMoneyStreakBonus: 10,
+	GetMoneyMaxStreak:   7,
+	Timezone:            "UTC",
+	ReliefMoney:         1000,
+	ReliefCooldown:      time.Hour,
+	GiveDailyLimit:      20000,
+	GiveMinAccountAge:   7 * 24 * time.Hour,
+	SeasonDays:          0,
+	Language:            "en",
+	CardStyle:           "image",
+	CardTheme:           "classic",
+	// Leave WebhookURL empty to use long polling.
+	WebhookURL:    "",
+	WebhookListen: ":8443",
+	WebhookSecret: "",
+	SmallBlind:    50,
+	BigBlind:      100,
+	MinBuyIn:      100,
+	MaxBuyIn:      5000,
+	GiveTimeout:   time.Minute,
+	TableTimeout:  30 * time.Second,
+	TableIdle:     10 * time.Minute,
+	InGameButtons: []*bot.KeyboardButton{
+		&bot.KeyboardButton{Text: "/startgame"},
+		&bot.KeyboardButton{Text: "/leave"},
+	},
+	OutButtons: []*bot.KeyboardButton{
+		&bot.KeyboardButton{Text: "/join"},
+		&bot.KeyboardButton{Text: "/getmoney"},
+	},
+}
+
+var (
+	currentBot atomic.Value
+	// Serializes the changes of the configuration.
+	botMutex sync.Mutex
+)
+
+func init() {
+	bot := defaultBot
+	currentBot.Store(&bot)
+}
+
+// Bot returns the configuration of the bot. A reload replaces it as a whole
+// while handlers read it, so it must not be changed; use UpdateBot instead.
+func Bot() *BotConfig {
+	return currentBot.Load().(*BotConfig)
+}
+
+// UpdateBot changes a copy of the configuration of the bot and publishes it.
+func UpdateBot(change func(bot *BotConfig)) {
+	botMutex.Lock()
+	defer botMutex.Unlock()
+	bot := *Bot()
+	change(&bot)
+	currentBot.Store(&bot)
+}
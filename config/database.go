@@ -1,9 +1,23 @@
 package config
 
 import (
+	"sync/atomic"
+
 	"gopkg.in/redis.v5"
 )
 
-var Database *redis.Options = &redis.Options{
+var defaultDatabase = redis.Options{
 	Addr: "localhost:6379",
 }
+
+var currentDatabase atomic.Value
+
+func init() {
+	database := defaultDatabase
+	currentDatabase.Store(&database)
+}
+
+// Database returns the options of Redis. It must not be changed.
+func Database() *redis.Options {
+	return currentDatabase.Load().(*redis.Options)
+}
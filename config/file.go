@@ -0,0 +1,380 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Choices of language, cards.style and cards.theme, which the bot sets
+// before Load. Anything goes while they are empty.
+var (
+	Languages  []string
+	CardStyles []string
+	CardThemes []string
+)
+
+// File is the configuration file. Every value can be overridden by an
+// environment variable named by its path, e.g. TEXAS_REDIS_ADDR for
+// redis.addr. Lists in the environment are separated by commas.
+type File struct {
+	Token    string `yaml:"token"`
+	Language string `yaml:"language"`
+	Admins   []int  `yaml:"admins"`
+	Redis    struct {
+		Addr     string `yaml:"addr"`
+		Password string `yaml:"password"`
+		DB       int    `yaml:"db"`
+	} `yaml:"redis"`
+	Stakes struct {
+		SmallBlind int64 `yaml:"small_blind"`
+		BigBlind   int64 `yaml:"big_blind"`
+	} `yaml:"stakes"`
+	BuyIn struct {
+		Min int64 `yaml:"min"`
+		Max int64 `yaml:"max"`
+	} `yaml:"buy_in"`
+	Money struct {
+		Base              int64         `yaml:"base"`
+		Bonus             int64         `yaml:"bonus"`
+		StreakBonus       int64         `yaml:"streak_bonus"`
+		MaxStreak         int64         `yaml:"max_streak"`
+		Timezone          string        `yaml:"timezone"`
+		Relief            int64         `yaml:"relief"`
+		ReliefCooldown    time.Duration `yaml:"relief_cooldown"`
+		GiveDailyLimit    int64         `yaml:"give_daily_limit"`
+		GiveMinAccountAge time.Duration `yaml:"give_min_account_age"`
+	} `yaml:"money"`
+	Timeouts struct {
+		Give      time.Duration `yaml:"give"`
+		Table     time.Duration `yaml:"table"`
+		TableIdle time.Duration `yaml:"table_idle"`
+	} `yaml:"timeouts"`
+	SeasonDays int `yaml:"season_days"`
+	Webhook    struct {
+		URL    string `yaml:"url"`
+		Listen string `yaml:"listen"`
+		Secret string `yaml:"secret"`
+		Cert   string `yaml:"cert"`
+		Key    string `yaml:"key"`
+	} `yaml:"webhook"`
+	Cards struct {
+		Style string `yaml:"style"`
+		Theme string `yaml:"theme"`
+		Art   string `yaml:"art"`
+	} `yaml:"cards"`
+	Metrics struct {
+		Listen string `yaml:"listen"`
+	} `yaml:"metrics"`
+	API struct {
+		Listen string `yaml:"listen"`
+		Secret string `yaml:"secret"`
+		URL    string `yaml:"url"`
+		Origin string `yaml:"origin"`
+		Signup bool   `yaml:"signup"`
+	} `yaml:"api"`
+	// Stickers are file IDs by card notation like "As" or "Td".
+	Stickers map[string]string `yaml:"stickers"`
+}
+
+// The file with the default values.
+func defaultFile() *File {
+	f := &File{}
+	f.Token = defaultBot.Token
+	f.Language = defaultBot.Language
+	f.Admins = defaultBot.Admins
+	f.Redis.Addr = defaultDatabase.Addr
+	f.Redis.Password = defaultDatabase.Password
+	f.Redis.DB = defaultDatabase.DB
+	f.Stakes.SmallBlind = defaultBot.SmallBlind
+	f.Stakes.BigBlind = defaultBot.BigBlind
+	f.BuyIn.Min = defaultBot.MinBuyIn
+	f.BuyIn.Max = defaultBot.MaxBuyIn
+	f.Money.Base = defaultBot.GetMoneyBase
+	f.Money.Bonus = defaultBot.GetMoneyBonus
+	f.Money.StreakBonus = defaultBot.GetMoneyStreakBonus
+	f.Money.MaxStreak = defaultBot.GetMoneyMaxStreak
+	f.Money.Timezone = defaultBot.Timezone
+	f.Money.Relief = defaultBot.ReliefMoney
+	f.Money.ReliefCooldown = defaultBot.ReliefCooldown
+	f.Money.GiveDailyLimit = defaultBot.GiveDailyLimit
+	f.Money.GiveMinAccountAge = defaultBot.GiveMinAccountAge
+	f.Timeouts.Give = defaultBot.GiveTimeout
+	f.Timeouts.Table = defaultBot.TableTimeout
+	f.Timeouts.TableIdle = defaultBot.TableIdle
+	f.SeasonDays = defaultBot.SeasonDays
+	f.Webhook.URL = defaultBot.WebhookURL
+	f.Webhook.Listen = defaultBot.WebhookListen
+	f.Webhook.Secret = defaultBot.WebhookSecret
+	f.Webhook.Cert = defaultBot.WebhookCert
+	f.Webhook.Key = defaultBot.WebhookKey
+	f.Cards.Style = defaultBot.CardStyle
+	f.Cards.Theme = defaultBot.CardTheme
+	f.Cards.Art = defaultBot.CardArt
+	f.Metrics.Listen = defaultBot.MetricsListen
+	f.API.Listen = defaultBot.APIListen
+	f.API.Secret = defaultBot.APISecret
+	f.API.URL = defaultBot.APIURL
+	f.API.Origin = defaultBot.APIOrigin
+	f.API.Signup = defaultBot.APISignup
+	return f
+}
+
+var notationRanks = "23456789TJQKA"
+var notationSuits = "dhcs"
+
+// Find the suit and rank of a card notation like "As".
+func parseNotation(notation string) (int, int, bool) {
+	if len(notation) != 2 {
+		return 0, 0, false
+	}
+	rank := strings.IndexByte(notationRanks, notation[0])
+	suit := strings.IndexByte(notationSuits, notation[1])
+	if rank < 0 || suit < 0 {
+		return 0, 0, false
+	}
+	return suit, rank + 2, true
+}
+
+// Set the value from an environment variable.
+func setFromEnv(value reflect.Value, text string) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Slice:
+		fields := strings.Split(text, ",")
+		slice := reflect.MakeSlice(value.Type(), len(fields), len(fields))
+		for i, field := range fields {
+			err := setFromEnv(slice.Index(i), strings.TrimSpace(field))
+			if err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+	case reflect.Map:
+		// Maps are too long for the environment.
+	}
+	return nil
+}
+
+// Override values of the struct by environment variables named by prefix
+// and their yaml names.
+func overrideFromEnv(value reflect.Value, prefix string) []string {
+	problems := make([]string, 0)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := prefix + "_" + strings.ToUpper(field.Tag.Get("yaml"))
+		if field.Type.Kind() == reflect.Struct {
+			problems = append(problems,
+				overrideFromEnv(value.Field(i), name)...)
+			continue
+		}
+		text, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		err := setFromEnv(value.Field(i), text)
+		if err != nil {
+			problems = append(problems, name+": "+err.Error())
+		}
+	}
+	return problems
+}
+
+// Check the values and return every problem.
+func (f *File) validate() []string {
+	problems := make([]string, 0)
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	choose := func(name string, value string, choices []string) {
+		if len(choices) == 0 {
+			return
+		}
+		for _, choice := range choices {
+			if value == choice {
+				return
+			}
+		}
+		problems = append(problems, fmt.Sprintf("%s %q is unknown, use "+
+			"one of %s", name, value, strings.Join(choices, ", ")))
+	}
+	check(f.Token != "", "token is required, set it in the file or TEXAS_TOKEN")
+	check(f.Redis.Addr != "", "redis.addr is required")
+	check(f.Redis.DB >= 0, "redis.db must not be negative")
+	check(f.Stakes.SmallBlind > 0, "stakes.small_blind must be positive")
+	check(f.Stakes.BigBlind >= f.Stakes.SmallBlind,
+		"stakes.big_blind must not be less than stakes.small_blind")
+	check(f.BuyIn.Min >= f.Stakes.BigBlind,
+		"buy_in.min must not be less than stakes.big_blind")
+	check(f.BuyIn.Max >= f.BuyIn.Min,
+		"buy_in.max must not be less than buy_in.min")
+	check(f.Money.Base >= 0, "money.base must not be negative")
+	check(f.Money.Bonus > 0, "money.bonus must be positive")
+	check(f.Money.StreakBonus >= 0, "money.streak_bonus must not be negative")
+	check(f.Money.MaxStreak >= 1, "money.max_streak must be at least 1")
+	check(f.Money.Relief >= 0, "money.relief must not be negative")
+	check(f.Money.ReliefCooldown >= 0,
+		"money.relief_cooldown must not be negative")
+	check(f.Money.GiveDailyLimit >= 0,
+		"money.give_daily_limit must not be negative")
+	check(f.Money.GiveMinAccountAge >= 0,
+		"money.give_min_account_age must not be negative")
+	if _, err := time.LoadLocation(f.Money.Timezone); err != nil {
+		problems = append(problems, "money.timezone: "+err.Error())
+	}
+	check(f.Timeouts.Give > 0, "timeouts.give must be positive")
+	check(f.Timeouts.Table > 0, "timeouts.table must be positive")
+	check(f.Timeouts.TableIdle > 0, "timeouts.table_idle must be positive")
+	check(f.SeasonDays >= 0, "season_days must not be negative")
+	choose("language", f.Language, Languages)
+	choose("cards.style", f.Cards.Style, CardStyles)
+	choose("cards.theme", f.Cards.Theme, CardThemes)
+	check(f.Webhook.URL == "" || f.Webhook.Secret != "",
+		"webhook.secret is required with webhook.url")
+	check((f.Webhook.Cert == "") == (f.Webhook.Key == ""),
+		"webhook.cert and webhook.key must be set together")
+	check(f.API.Listen == "" || len(f.API.Secret) >= 16,
+		"api.secret of at least 16 characters is required with api.listen")
+	for notation := range f.Stickers {
+		_, _, ok := parseNotation(notation)
+		check(ok, "stickers.%s is not a card like As or Td", notation)
+	}
+	return problems
+}
+
+// Read the file and the environment. A missing file leaves the defaults.
+func read(path string) (*File, error) {
+	f := defaultFile()
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = yaml.Unmarshal(data, f)
+		if err != nil {
+			return nil, errors.New(path + ": " + err.Error())
+		}
+	}
+	problems := overrideFromEnv(reflect.ValueOf(f).Elem(), "TEXAS")
+	problems = append(problems, f.validate()...)
+	if len(problems) > 0 {
+		return nil, errors.New(path + ":\n  " + strings.Join(problems, "\n  "))
+	}
+	return f, nil
+}
+
+// Apply the values to the configuration of the bot.
+func (f *File) apply() {
+	botMutex.Lock()
+	defer botMutex.Unlock()
+	bot := defaultBot
+	bot.ID = Bot().ID
+	bot.Username = Bot().Username
+	bot.Token = f.Token
+	bot.Language = f.Language
+	bot.Admins = f.Admins
+	bot.SmallBlind = f.Stakes.SmallBlind
+	bot.BigBlind = f.Stakes.BigBlind
+	bot.MinBuyIn = f.BuyIn.Min
+	bot.MaxBuyIn = f.BuyIn.Max
+	bot.GetMoneyBase = f.Money.Base
+	bot.GetMoneyBonus = f.Money.Bonus
+	bot.GetMoneyStreakBonus = f.Money.StreakBonus
+	bot.GetMoneyMaxStreak = f.Money.MaxStreak
+	bot.Timezone = f.Money.Timezone
+	bot.ReliefMoney = f.Money.Relief
+	bot.ReliefCooldown = f.Money.ReliefCooldown
+	bot.GiveDailyLimit = f.Money.GiveDailyLimit
+	bot.GiveMinAccountAge = f.Money.GiveMinAccountAge
+	bot.GiveTimeout = f.Timeouts.Give
+	bot.TableTimeout = f.Timeouts.Table
+	bot.TableIdle = f.Timeouts.TableIdle
+	bot.SeasonDays = f.SeasonDays
+	bot.WebhookURL = f.Webhook.URL
+	bot.WebhookListen = f.Webhook.Listen
+	bot.WebhookSecret = f.Webhook.Secret
+	bot.WebhookCert = f.Webhook.Cert
+	bot.WebhookKey = f.Webhook.Key
+	bot.CardStyle = f.Cards.Style
+	bot.CardTheme = f.Cards.Theme
+	bot.CardArt = f.Cards.Art
+	bot.MetricsListen = f.Metrics.Listen
+	bot.APIListen = f.API.Listen
+	bot.APISecret = f.API.Secret
+	bot.APIURL = f.API.URL
+	bot.APIOrigin = f.API.Origin
+	bot.APISignup = f.API.Signup
+	database := defaultDatabase
+	database.Addr = f.Redis.Addr
+	database.Password = f.Redis.Password
+	database.DB = f.Redis.DB
+	fileIDs := defaultPokerFileIDs
+	for notation, fileID := range f.Stickers {
+		suit, rank, _ := parseNotation(notation)
+		fileIDs[suit][rank] = fileID
+	}
+	currentBot.Store(&bot)
+	currentDatabase.Store(&database)
+	currentPokerFileIDs.Store(&fileIDs)
+}
+
+// Load the configuration from the file at path and the environment.
+func Load(path string) error {
+	f, err := read(path)
+	if err != nil {
+		return err
+	}
+	f.apply()
+	return nil
+}
+
+// Load the configuration again while the bot runs. The token and Redis
+// are kept until restart.
+func Reload(path string) error {
+	f, err := read(path)
+	if err != nil {
+		return err
+	}
+	bot, database := Bot(), Database()
+	if f.Token != bot.Token || f.Redis.Addr != database.Addr ||
+		f.Redis.Password != database.Password || f.Redis.DB != database.DB {
+		log.Println("Token and Redis changes take effect after restart.")
+	}
+	f.Token = bot.Token
+	f.Redis.Addr = database.Addr
+	f.Redis.Password = database.Password
+	f.Redis.DB = database.DB
+	f.apply()
+	return nil
+}
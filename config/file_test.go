@@ -0,0 +1,33 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateChoices(t *testing.T) {
+	Languages = []string{"en", "zh"}
+	CardStyles = []string{"image", "emoji"}
+	CardThemes = []string{"classic", "dark"}
+	defer func() {
+		Languages, CardStyles, CardThemes = nil, nil, nil
+	}()
+	f := defaultFile()
+	f.Token = "token"
+	if problems := f.validate(); len(problems) > 0 {
+		t.Fatalf("Defaults have problems: %v", problems)
+	}
+	f.Language = "fr"
+	f.Cards.Style = "smoke"
+	f.Cards.Theme = "neon"
+	text := strings.Join(f.validate(), "\n")
+	for _, want := range []string{
+		`language "fr" is unknown, use one of en, zh`,
+		`cards.style "smoke" is unknown, use one of image, emoji`,
+		`cards.theme "neon" is unknown, use one of classic, dark`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("No %q in problems:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+// Package telegramtest fakes the Telegram Bot API for end-to-end tests.
+// Scripted users send updates through the server, and it records every
+// message the bot sends.
+package telegramtest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magicae/texas-holdem-bot/botapi"
+)
+
+// The bot the server pretends to be.
+const (
+	BotID       = 1000
+	BotUsername = "TexasTestBot"
+)
+
+// Longest wait of getUpdates, so pollers notice a closed server soon.
+const maxPollWait = time.Second
+
+// User is a user of the Bot API.
+type User struct {
+	ID        int    `json:"id"`
+	IsBot     bool   `json:"is_bot,omitempty"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+// Chat is a chat of the Bot API.
+type Chat struct {
+	ID    int64  `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title,omitempty"`
+}
+
+// Message is a message of the Bot API.
+type Message struct {
+	MessageID      int      `json:"message_id"`
+	From           *User    `json:"from,omitempty"`
+	Chat           *Chat    `json:"chat"`
+	Date           int64    `json:"date"`
+	Text           string   `json:"text,omitempty"`
+	ReplyToMessage *Message `json:"reply_to_message,omitempty"`
+}
+
+// CallbackQuery is a pressed inline button.
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    *User    `json:"from"`
+	Message *Message `json:"message"`
+	Data    string   `json:"data"`
+}
+
+// Update is an update for the bot.
+type Update struct {
+	UpdateID      int            `json:"update_id"`
+	Message       *Message       `json:"message,omitempty"`
+	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+}
+
+// Sent is a call of the bot which sends or changes a message.
+type Sent struct {
+	Method    string
+	ChatID    int64
+	MessageID int
+	// Text of a message, or caption of a file.
+	Text string
+	// Inline buttons of the message, in order.
+	Buttons []botapi.Button
+	Params  map[string]string
+}
+
+// Server is a fake of the Bot API.
+type Server struct {
+	*httptest.Server
+	mutex     sync.Mutex
+	updates   []*Update
+	updateID  int
+	messageID int
+	queryID   int
+	sent      []*Sent
+	// Text and buttons of the messages of the bot, to refuse edits like
+	// Telegram does.
+	contents map[messageKey]string
+	// Closed and replaced whenever an update or a message is added.
+	changed chan struct{}
+}
+
+type messageKey struct {
+	ChatID    int64
+	MessageID int
+}
+
+// Errors of editMessageText as Telegram words them.
+var (
+	ErrNotModified = errors.New("message is not modified: specified new " +
+		"message content and reply markup are exactly the same as a " +
+		"current content and reply markup of the message")
+	ErrMessageNotFound = errors.New("message to edit not found")
+)
+
+// Start a server.
+func NewServer() *Server {
+	s := &Server{
+		contents: map[messageKey]string{},
+		changed:  make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Wake up everyone waiting for a change. The caller holds the mutex.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Transport sends the calls for api.telegram.org to the server instead, and
+// the other requests to next.
+func (s *Server) Transport(next http.RoundTripper) http.RoundTripper {
+	target, _ := url.Parse(s.URL)
+	return &transport{target: target, next: next}
+}
+
+type transport struct {
+	target *url.URL
+	next   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host == "api.telegram.org" {
+		copied := *r
+		u := *r.URL
+		u.Scheme = t.target.Scheme
+		u.Host = t.target.Host
+		copied.URL = &u
+		copied.Host = t.target.Host
+		r = &copied
+	}
+	return t.next.RoundTrip(r)
+}
+
+// Add an update for the bot.
+func (s *Server) addUpdate(update *Update) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.updateID++
+	update.UpdateID = s.updateID
+	s.updates = append(s.updates, update)
+	s.notify()
+}
+
+// Send a text message from the user to the chat. Returns its ID.
+func (s *Server) SendMessage(chat *Chat, from *User, text string) int {
+	return s.Reply(chat, from, text, 0)
+}
+
+// Send a text message replying to a message of the bot. Returns its ID.
+func (s *Server) Reply(chat *Chat, from *User, text string,
+	replyTo int) int {
+	s.mutex.Lock()
+	s.messageID++
+	message := &Message{
+		MessageID: s.messageID,
+		From:      from,
+		Chat:      chat,
+		Date:      time.Now().Unix(),
+		Text:      text,
+	}
+	s.mutex.Unlock()
+	if replyTo != 0 {
+		message.ReplyToMessage = &Message{
+			MessageID: replyTo,
+			From:      botUser(),
+			Chat:      chat,
+		}
+	}
+	s.addUpdate(&Update{Message: message})
+	return message.MessageID
+}
+
+// Press an inline button of a message of the bot.
+func (s *Server) PressButton(chat *Chat, from *User, messageID int,
+	data string) {
+	s.mutex.Lock()
+	s.queryID++
+	id := strconv.Itoa(s.queryID)
+	s.mutex.Unlock()
+	s.addUpdate(&Update{CallbackQuery: &CallbackQuery{
+		ID:   id,
+		From: from,
+		Message: &Message{
+			MessageID: messageID,
+			From:      botUser(),
+			Chat:      chat,
+		},
+		Data: data,
+	}})
+}
+
+// Delete a message of the bot, as a user may do.
+func (s *Server) DeleteMessage(chatID int64, messageID int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.contents, messageKey{chatID, messageID})
+}
+
+// Every call recorded so far, the oldest first.
+func (s *Server) Sent() []*Sent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sent := make([]*Sent, len(s.sent))
+	copy(sent, s.sent)
+	return sent
+}
+
+// Wait for a call from the nth on which matches, and return it with its
+// index. Returns nil if none comes in time.
+func (s *Server) Wait(n int, timeout time.Duration,
+	match func(*Sent) bool) (*Sent, int) {
+	deadline := time.After(timeout)
+	for {
+		s.mutex.Lock()
+		for ; n < len(s.sent); n++ {
+			if match(s.sent[n]) {
+				s.mutex.Unlock()
+				return s.sent[n], n
+			}
+		}
+		changed := s.changed
+		s.mutex.Unlock()
+		select {
+		case <-changed:
+		case <-deadline:
+			return nil, n
+		}
+	}
+}
+
+func botUser() *User {
+	return &User{
+		ID:        BotID,
+		IsBot:     true,
+		FirstName: "Texas",
+		Username:  BotUsername,
+	}
+}
+
+// Return the updates from offset, waiting for one up to timeout seconds.
+func (s *Server) getUpdates(params map[string]string) []*Update {
+	offset, _ := strconv.Atoi(params["offset"])
+	seconds, _ := strconv.Atoi(params["timeout"])
+	wait := time.Duration(seconds) * time.Second
+	if wait > maxPollWait {
+		wait = maxPollWait
+	}
+	deadline := time.After(wait)
+	for {
+		s.mutex.Lock()
+		updates := make([]*Update, 0)
+		for _, update := range s.updates {
+			if update.UpdateID >= offset {
+				updates = append(updates, update)
+			}
+		}
+		// Confirmed updates are not sent again.
+		s.updates = updates
+		changed := s.changed
+		s.mutex.Unlock()
+		if len(updates) > 0 {
+			return updates
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			return updates
+		}
+	}
+}
+
+// Record a message sent or edited by the bot and return it. Edits of
+// deleted messages, and edits which change nothing, are refused.
+func (s *Server) record(method string, params map[string]string) (*Message,
+	error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+	messageID, _ := strconv.Atoi(params["message_id"])
+	text := params["text"]
+	if text == "" {
+		text = params["caption"]
+	}
+	content := text + "\x00" + params["reply_markup"]
+	if method == "editMessageText" {
+		old, ok := s.contents[messageKey{chatID, messageID}]
+		if !ok {
+			return nil, ErrMessageNotFound
+		}
+		if old == content {
+			return nil, ErrNotModified
+		}
+	} else {
+		s.messageID++
+		messageID = s.messageID
+	}
+	if method != "answerCallbackQuery" {
+		s.contents[messageKey{chatID, messageID}] = content
+	}
+	s.sent = append(s.sent, &Sent{
+		Method:    method,
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+		Buttons:   botapi.ReadButtons(params["reply_markup"]),
+		Params:    params,
+	})
+	s.notify()
+	chatType := "private"
+	if chatID < 0 {
+		chatType = "group"
+	}
+	return &Message{
+		MessageID: messageID,
+		From:      botUser(),
+		Chat:      &Chat{ID: chatID, Type: chatType},
+		Date:      time.Now().Unix(),
+		Text:      text,
+	}, nil
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{"ok": true}
+	params, err := botapi.ReadParams(r)
+	if err == nil && !strings.HasPrefix(r.URL.Path, "/bot") {
+		err = errors.New("Not Found")
+	}
+	if err == nil {
+		switch method := path.Base(r.URL.Path); method {
+		case "getMe":
+			response["result"] = botUser()
+		case "getUpdates":
+			response["result"] = s.getUpdates(params)
+		case "getChatAdministrators":
+			response["result"] = []interface{}{}
+		case "sendMessage", "editMessageText", "sendSticker", "sendPhoto",
+			"sendDocument", "answerCallbackQuery":
+			var message *Message
+			message, err = s.record(method, params)
+			if method == "answerCallbackQuery" {
+				response["result"] = true
+			} else {
+				response["result"] = message
+			}
+		default:
+			response["result"] = true
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response = map[string]interface{}{
+			"ok":          false,
+			"error_code":  http.StatusBadRequest,
+			"description": "Bad Request: " + err.Error(),
+		}
+	}
+	json.NewEncoder(w).Encode(response)
+}
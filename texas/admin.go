@@ -0,0 +1,237 @@
+package texas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	. "github.com/magicae/telegram-bot"
+)
+
+var ErrTablePaused = errors.New("Table is paused. Table owners can /resume it.")
+
+// Whether the user is an administrator of the group.
+func isGroupAdmin(chatID int64, userID int) bool {
+	result, err := callTelegram("getChatAdministrators",
+		map[string]interface{}{"chat_id": chatID})
+	if err != nil {
+		log.Println("Error:", err, "< isGroupAdmin")
+		return false
+	}
+	var members []struct {
+		User struct {
+			ID int `json:"id"`
+		} `json:"user"`
+	}
+	err = json.Unmarshal(result, &members)
+	if err != nil {
+		log.Println("Error:", err, "< isGroupAdmin")
+		return false
+	}
+	for _, member := range members {
+		if member.User.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the user can control the table: the one who started it, group
+// administrators and bot admins.
+func isTableOwner(game *Texas, userID int) bool {
+	return game.Owner == userID || isAdmin(userID) ||
+		isGroupAdmin(game.ChatID, userID)
+}
+
+func logAdmin(chatID int64, user *User, action string) {
+	log.Println("Admin:", user.ID, action, "at", chatID)
+}
+
+// Get the game if the user owns it, or reply why not. Returns nil if the
+// user can not control it.
+func getOwnedGame(e *Bot, id int, chat *Chat, user *User,
+	command string) (*Texas, error) {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	text := ""
+	if game == nil {
+		text = tr(lang, "Game is not ready.")
+	} else if !isTableOwner(game, user.ID) {
+		text = trf(lang, "Only the table owner and group admins can /%s.",
+			command)
+	} else {
+		return game, nil
+	}
+	_, err := e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	})
+	return nil, err
+}
+
+func handleKick(e *Bot, id int, chat *Chat, user *User, target *User,
+	args string) error {
+	lang := getLanguage(chat.ID)
+	game, err := getOwnedGame(e, id, chat, user, "kick")
+	if game == nil {
+		return err
+	}
+	if strings.HasPrefix(args, "@") {
+		targetID, err := store.UserByName(args[1:])
+		if err != nil {
+			_, err = e.SendMessage(&SendMessageRequest{
+				ChatID:           chat.ID,
+				Text:             tr(lang, err.Error()),
+				ReplyToMessageID: id,
+			})
+			return err
+		}
+		target = &User{ID: targetID}
+	} else if args != "" || target == nil {
+		return errUsage
+	}
+	name := ""
+	for i := 0; i < 10; i++ {
+		if game.Players[i] != nil && game.Players[i].UserID == target.ID {
+			name = game.Players[i].DisplayName
+		}
+	}
+	chip, err := leaveGame(game, target)
+	text := ""
+	if err != nil {
+		text = tr(lang, "The user is not in this game.")
+	} else {
+		logAdmin(chat.ID, user, fmt.Sprint("kicked ", target.ID))
+		text = trf(lang, "%s is kicked and took $%d back.", name, chip)
+		if game.CountUser() == 0 {
+			text += " " + tr(lang, "Game ends!")
+		}
+	}
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	})
+	return err
+}
+
+func handlePause(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game, err := getOwnedGame(e, id, chat, user, "pause")
+	if game == nil {
+		return err
+	}
+	game.Paused = true
+	logAdmin(chat.ID, user, "paused")
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID: chat.ID,
+		Text: trf(lang, "%s paused the table. Nobody can act until /resume.",
+			getUserDisplayName(user)),
+		ReplyToMessageID: id,
+	})
+	return err
+}
+
+func handleResume(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game, err := getOwnedGame(e, id, chat, user, "resume")
+	if game == nil {
+		return err
+	}
+	game.Paused = false
+	logAdmin(chat.ID, user, "resumed")
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID: chat.ID,
+		Text: trf(lang, "%s resumed the table.",
+			getUserDisplayName(user)),
+		ReplyToMessageID: id,
+	})
+	if err != nil {
+		return err
+	}
+	if game.Round != nil && game.Round.Stage < End {
+		// Post the table again below the messages of the pause.
+		game.Round.StatusMessageID = 0
+		return game.ShowStatus()
+	}
+	return nil
+}
+
+func handlePublic(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game, err := getOwnedGame(e, id, chat, user, "public")
+	if game == nil {
+		return err
+	}
+	game.Public = true
+	logAdmin(chat.ID, user, "made the table public")
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID: chat.ID,
+		Text: trf(lang, "%s made the table public. Web players can find "+
+			"and join it.", getUserDisplayName(user)),
+		ReplyToMessageID: id,
+	})
+	return err
+}
+
+func handlePrivate(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game, err := getOwnedGame(e, id, chat, user, "private")
+	if game == nil {
+		return err
+	}
+	game.Public = false
+	logAdmin(chat.ID, user, "made the table private")
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID: chat.ID,
+		Text: trf(lang, "%s made the table private. Only its players see "+
+			"it on the web.", getUserDisplayName(user)),
+		ReplyToMessageID: id,
+	})
+	return err
+}
+
+// Cancel the round and cash everyone out. Returns the text of the chips
+// everyone took back.
+func endGame(game *Texas) string {
+	lang := game.Lang()
+	game.CancelRound()
+	text := ""
+	count := 0
+	for i := 0; i < 10; i++ {
+		player := game.Players[i]
+		if player == nil {
+			continue
+		}
+		count++
+		chip, err := game.RemoveUser(&User{ID: player.UserID})
+		if err != nil {
+			log.Println("Error:", err, "< endGame")
+			continue
+		}
+		text += fmt.Sprintf("[%d] %s", count, player.DisplayName) + " " +
+			trn(lang, chip, "-> %d chip.", "-> %d chips.", chip) + "\n"
+	}
+	setGame(game.ChatID, nil)
+	return text
+}
+
+func handleEndGame(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game, err := getOwnedGame(e, id, chat, user, "endgame")
+	if game == nil {
+		return err
+	}
+	text := endGame(game)
+	logAdmin(chat.ID, user, "ended the game")
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID: chat.ID,
+		Text: trf(lang, "%s ended the game. Everyone took the chips back:",
+			getUserDisplayName(user)) + "\n" + text,
+		ReplyToMessageID: id,
+	})
+	return err
+}
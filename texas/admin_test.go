@@ -0,0 +1,18 @@
+package texas
+
+import "testing"
+
+func TestEndGameRefundsLeftPlayers(t *testing.T) {
+	table := newE2ETable(t, "Kate", "Liam", "Mia")
+	table.start("Kate")
+	raiser := table.actor()
+	table.say(raiser, "/raise 200")
+	table.nextStatus()
+	// Leaving folds, and the bets stay in the pot.
+	table.say(raiser, "/leave")
+	table.expect(table.chat.ID, "Bye! You took $")
+	table.say("Kate", "/endgame")
+	table.expect(table.chat.ID, "Kate ended the game. "+
+		"Everyone took the chips back:")
+	table.checkWallets()
+}
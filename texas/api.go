@@ -0,0 +1,799 @@
+package texas
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// How long a token of /web is valid.
+const apiTokenAge = 30 * 24 * time.Hour
+
+// Events queued for a web client at most. Slow clients miss events but get
+// the latest table with the next one.
+const apiEventQueue = 16
+
+// How often web clients are pinged to keep the connection.
+const apiPingInterval = 30 * time.Second
+
+var ErrBadToken = errors.New("Invalid or expired token.")
+
+// Names of users signing up on the web. Their usernames get the prefix, so
+// they can be told from Telegram users.
+var webNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,20}$`)
+
+const webUsernamePrefix = "web_"
+
+// apiClaims are who a token of /web is for.
+type apiClaims struct {
+	UserID   int    `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Expires  int64  `json:"exp"`
+}
+
+// apiSeat is a player at a table.
+type apiSeat struct {
+	Seat   int    `json:"seat"`
+	UserID int    `json:"user_id"`
+	Name   string `json:"name"`
+	Chip   int64  `json:"chip"`
+	// Bet of this stage and of the hand.
+	Bet      int64 `json:"bet"`
+	TotalBet int64 `json:"total_bet"`
+	// out of the hand, in_game or fold.
+	State string   `json:"state"`
+	AllIn bool     `json:"all_in,omitempty"`
+	Cards []string `json:"cards,omitempty"`
+	Win   int64    `json:"win,omitempty"`
+}
+
+// apiAction is an action the user can take. Raises are totals of the stage.
+type apiAction struct {
+	Action string `json:"action"`
+	Amount int64  `json:"amount,omitempty"`
+	To     int64  `json:"to,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// apiTable is a table as a user sees it.
+type apiTable struct {
+	ChatID     int64       `json:"chat_id"`
+	Stage      string      `json:"stage"`
+	Pot        int64       `json:"pot"`
+	Board      []string    `json:"board"`
+	Dealer     int         `json:"dealer"`
+	Actor      int         `json:"actor"`
+	SmallBlind int64       `json:"small_blind"`
+	BigBlind   int64       `json:"big_blind"`
+	Paused     bool        `json:"paused"`
+	Seats      []*apiSeat  `json:"seats"`
+	You        int         `json:"you"`
+	Actions    []apiAction `json:"actions"`
+}
+
+// apiEvent is pushed to web clients watching a table. Table is nil once the
+// game ends or the user can not see it.
+type apiEvent struct {
+	Type  string    `json:"type"`
+	Table *apiTable `json:"table"`
+}
+
+// apiActionRequest is an action posted by a web client.
+type apiActionRequest struct {
+	Action string `json:"action"`
+	// Total bet of the stage to raise to.
+	To int64 `json:"to"`
+}
+
+var seatStates = []string{"out", "in_game", "fold"}
+
+// apiSignUpRequest is a user signing up on the web.
+type apiSignUpRequest struct {
+	Name string `json:"name"`
+}
+
+// Sign the claims into a token, the claims and their HMAC in base64.
+func signToken(claims *apiClaims) string {
+	data, _ := json.Marshal(claims)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	mac := hmac.New(sha256.New, []byte(config.Bot().APISecret))
+	mac.Write([]byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Check the token and return its claims.
+func verifyToken(token string) (*apiClaims, error) {
+	i := strings.Index(token, ".")
+	if i < 0 || config.Bot().APISecret == "" {
+		return nil, ErrBadToken
+	}
+	payload := token[:i]
+	signature, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil {
+		return nil, ErrBadToken
+	}
+	mac := hmac.New(sha256.New, []byte(config.Bot().APISecret))
+	mac.Write([]byte(payload))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrBadToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrBadToken
+	}
+	claims := &apiClaims{}
+	err = json.Unmarshal(data, claims)
+	if err != nil || time.Now().Unix() > claims.Expires {
+		return nil, ErrBadToken
+	}
+	return claims, nil
+}
+
+// Find the user of a request by the bearer token, or the token parameter
+// for WebSocket.
+func apiUser(r *http.Request) (*User, error) {
+	token := r.URL.Query().Get("token")
+	if header := r.Header.Get("Authorization"); header != "" {
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+	claims, err := verifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return &User{
+		ID:        claims.UserID,
+		FirstName: claims.Name,
+		Username:  claims.Username,
+	}, nil
+}
+
+// A token of the user valid for apiTokenAge.
+func newToken(user *User) string {
+	return signToken(&apiClaims{
+		UserID:   user.ID,
+		Name:     getUserDisplayName(user),
+		Username: user.Username,
+		Expires:  time.Now().Add(apiTokenAge).Unix(),
+	})
+}
+
+// Users who signed up on the web have negative IDs and no Telegram.
+func isWebUser(userID int) bool {
+	return userID < 0
+}
+
+// Language of the replies to the user, the one of the private chat.
+func apiLanguage(user *User) string {
+	if isWebUser(user.ID) {
+		return config.Bot().Language
+	}
+	return getLanguage(int64(user.ID))
+}
+
+func handleWeb(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	text := ""
+	if config.Bot().APIListen == "" {
+		text = tr(lang, "Playing on the web is not enabled.")
+	} else {
+		token := newToken(user)
+		days := int64(apiTokenAge / (24 * time.Hour))
+		text = trn(lang, days,
+			"Your token to play on the web, valid for %d day. Keep it secret:",
+			"Your token to play on the web, valid for %d days. Keep it secret:",
+			days) + "\n" + token
+		if config.Bot().APIURL != "" {
+			text += "\n" + config.Bot().APIURL + "#token=" +
+				url.QueryEscape(token)
+		}
+	}
+	_, err := e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	})
+	return err
+}
+
+// Cards in notation, "??" for unknown ones.
+func cardNotations(cards ...*PokerCard) []string {
+	notations := make([]string, 0, len(cards))
+	for _, card := range cards {
+		if card == nil {
+			notations = append(notations, "??")
+		} else {
+			notations = append(notations, getPokerNotation(card))
+		}
+	}
+	return notations
+}
+
+// Actions the actor can take, like the buttons of the table message.
+func (t *Texas) legalActions() []apiAction {
+	actor := t.Round.ActorIndex
+	call := t.getMaxBet() - t.Round.StageBets[actor]
+	chip := t.Players[actor].Chip
+	actions := make([]apiAction, 0)
+	if call <= 0 {
+		actions = append(actions, apiAction{Action: "check"})
+	} else if chip > call {
+		actions = append(actions, apiAction{Action: "call", Amount: call})
+	}
+	actions = append(actions, apiAction{Action: "fold"})
+	for _, option := range t.RaiseOptions() {
+		actions = append(actions, apiAction{
+			Action: "raise",
+			To:     option.To,
+			Name:   option.Name,
+		})
+	}
+	return append(actions, apiAction{
+		Action: "allin",
+		To:     t.Round.StageBets[actor] + chip,
+	})
+}
+
+// Whether the user can see the table on the web: its players, and everyone
+// if it is public.
+func (t *Texas) visibleTo(userID int) bool {
+	if t.Public {
+		return true
+	}
+	for i := 0; i < 10; i++ {
+		if t.Players[i] != nil && t.Players[i].UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// The table as the user sees it, with the hole cards of the user. Everyone
+// sees the cards shown down.
+func (t *Texas) apiTable(userID int) *apiTable {
+	table := &apiTable{
+		ChatID:     t.ChatID,
+		Stage:      "waiting",
+		Board:      []string{},
+		Dealer:     t.Dealer,
+		Actor:      -1,
+		SmallBlind: t.SmallBlind,
+		BigBlind:   t.BigBlind,
+		Paused:     t.Paused,
+		Seats:      []*apiSeat{},
+		You:        -1,
+		Actions:    []apiAction{},
+	}
+	round := t.Round
+	running := round != nil && round.Stage < End
+	shown := false
+	if round != nil {
+		table.Stage = strings.Replace(
+			strings.ToLower(StageNames[round.Stage]), " ", "_", -1)
+		table.Pot = round.Pot
+		for _, card := range round.CommunityCards {
+			if card != nil {
+				table.Board = append(table.Board, getPokerNotation(card))
+			}
+		}
+		shown = !running && len(table.Board) == 5 && t.CountUserInGame() > 1
+		if running {
+			table.Actor = round.ActorIndex
+		}
+	}
+	for i := 0; i < 10; i++ {
+		player := t.Players[i]
+		if player == nil {
+			continue
+		}
+		seat := &apiSeat{
+			Seat:   i,
+			UserID: player.UserID,
+			Name:   player.DisplayName,
+			Chip:   player.Chip,
+			State:  seatStates[Out],
+		}
+		if round != nil {
+			seat.Bet = round.StageBets[i]
+			seat.TotalBet = round.TotalBets[i]
+			seat.State = seatStates[round.UserState[i]]
+			seat.AllIn = round.UserState[i] == InGame && player.Chip == 0
+			seat.Win = round.Earn[i]
+			if round.PlayerCards[i][0] != nil && (player.UserID == userID ||
+				shown && round.UserState[i] == InGame) {
+				seat.Cards = cardNotations(round.PlayerCards[i][:]...)
+			}
+		}
+		if player.UserID == userID {
+			table.You = i
+		}
+		table.Seats = append(table.Seats, seat)
+	}
+	switch {
+	case table.You < 0:
+		table.Actions = append(table.Actions, apiAction{Action: "join"})
+	case running && table.Actor == table.You && !t.Paused:
+		table.Actions = append(t.legalActions(), apiAction{Action: "leave"})
+	case running:
+		table.Actions = append(table.Actions, apiAction{Action: "leave"})
+	default:
+		table.Actions = append(table.Actions, apiAction{Action: "startgame"},
+			apiAction{Action: "leave"})
+	}
+	return table
+}
+
+// apiWatcher is a web client watching a table.
+type apiWatcher struct {
+	UserID int
+	Events chan []byte
+}
+
+var (
+	watchers      = map[int64]map[*apiWatcher]bool{}
+	watchersMutex sync.Mutex
+)
+
+// Push the table of the chat to its watchers. It runs on the table.
+func publishTable(chatID int64, game *Texas) {
+	watchersMutex.Lock()
+	defer watchersMutex.Unlock()
+	for watcher := range watchers[chatID] {
+		event := &apiEvent{Type: "table"}
+		if game != nil && game.visibleTo(watcher.UserID) {
+			event.Table = game.apiTable(watcher.UserID)
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			log.Println("Error:", err, "< publishTable")
+			return
+		}
+		select {
+		case watcher.Events <- data:
+		default:
+		}
+	}
+}
+
+func watchTable(chatID int64, watcher *apiWatcher) {
+	watchersMutex.Lock()
+	defer watchersMutex.Unlock()
+	if watchers[chatID] == nil {
+		watchers[chatID] = map[*apiWatcher]bool{}
+	}
+	watchers[chatID][watcher] = true
+}
+
+func unwatchTable(chatID int64, watcher *apiWatcher) {
+	watchersMutex.Lock()
+	defer watchersMutex.Unlock()
+	delete(watchers[chatID], watcher)
+	if len(watchers[chatID]) == 0 {
+		delete(watchers, chatID)
+	}
+}
+
+// Run f on the table of the chat and wait for it. Returns ErrNoGame without
+// running f if the chat has no game.
+func callTable(chatID int64, f func()) error {
+	done, err := sendGame(chatID, f)
+	if err != nil {
+		return err
+	}
+	return waitTable(done)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, text string) {
+	writeJSON(w, status, map[string]string{"error": text})
+}
+
+// Write the error of callTable. Tables without a game are not found.
+func writeTableError(w http.ResponseWriter, err error) {
+	if err == ErrNoGame {
+		writeAPIError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeAPIError(w, http.StatusServiceUnavailable, err.Error())
+}
+
+// The table of the chat as the user sees it. Returns ErrNoGame if there is
+// no game or the user can not see it.
+func getAPITable(chatID int64, userID int) (*apiTable, error) {
+	result := make(chan *apiTable, 1)
+	err := callTable(chatID, func() {
+		game := getGame(chatID)
+		if game == nil || !game.visibleTo(userID) {
+			result <- nil
+			return
+		}
+		result <- game.apiTable(userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	table := <-result
+	if table == nil {
+		return nil, ErrNoGame
+	}
+	return table, nil
+}
+
+// List the tables the user can see.
+func handleAPITables(w http.ResponseWriter, r *http.Request, user *User) {
+	tablesMutex.Lock()
+	chatIDs := make([]int64, 0, len(tables))
+	for chatID, table := range tables {
+		if table.Game != nil {
+			chatIDs = append(chatIDs, chatID)
+		}
+	}
+	tablesMutex.Unlock()
+	list := make([]*apiTable, 0, len(chatIDs))
+	for _, chatID := range chatIDs {
+		table, err := getAPITable(chatID, user.ID)
+		if err == ErrNoGame {
+			continue
+		}
+		if err != nil {
+			log.Println("Error:", err, "< handleAPITables")
+			continue
+		}
+		list = append(list, table)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func handleAPITable(w http.ResponseWriter, r *http.Request, chatID int64,
+	user *User) {
+	table, err := getAPITable(chatID, user.ID)
+	if err != nil {
+		writeTableError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, table)
+}
+
+// Join the game of the chat, and tell the group like /join.
+func apiJoin(e *Bot, game *Texas, chat *Chat, user *User) (string, error) {
+	lang := getLanguage(chat.ID)
+	chip, err := game.AddUser(user)
+	if err != nil {
+		return tr(lang, "Failed to join game.") + " " + tr(lang, err.Error()),
+			nil
+	}
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID: chat.ID,
+		Text: trn(lang, chip, "%s (@%s) bought %d chip and joined the game!",
+			"%s (@%s) bought %d chips and joined the game!",
+			getUserDisplayName(user), user.Username, chip),
+	})
+	return "", err
+}
+
+// Take an action of the user at the table. Actions are join and the ones of
+// the table buttons.
+func handleAPIAction(e *Bot, w http.ResponseWriter, r *http.Request,
+	chatID int64, user *User) {
+	request := &apiActionRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Bad request.")
+		return
+	}
+	action := request.Action
+	switch action {
+	case "join", "startgame", "leave", "check", "call", "fold", "allin":
+	case "raise":
+		action = "raise:" + strconv.FormatInt(request.To, 10)
+	default:
+		writeAPIError(w, http.StatusBadRequest, "Unknown action.")
+		return
+	}
+	if isShuttingDown() {
+		writeAPIError(w, http.StatusServiceUnavailable, "Shutting down.")
+		return
+	}
+	refused := make(chan string, 1)
+	err = callTable(chatID, func() {
+		chat := &Chat{ID: chatID, Type: "group"}
+		game := getGame(chatID)
+		if game == nil || !game.visibleTo(user.ID) {
+			close(refused)
+			return
+		}
+		text := ""
+		var err error
+		if action == "join" {
+			text, err = apiJoin(e, game, chat, user)
+		} else {
+			text, err = takeTableAction(e, game, chat, user, action)
+		}
+		if err != nil {
+			log.Println("Error:", err, "< handleAPIAction")
+		}
+		err = saveGame(chatID)
+		if err != nil {
+			log.Println("Error:", err, "< saveGame")
+		}
+		refused <- text
+	})
+	if err != nil {
+		writeTableError(w, err)
+		return
+	}
+	text, ok := <-refused
+	if !ok {
+		writeTableError(w, ErrNoGame)
+		return
+	}
+	if text != "" {
+		writeAPIError(w, http.StatusConflict, text)
+		return
+	}
+	handleAPITable(w, r, chatID, user)
+}
+
+// Push the table to a WebSocket client whenever it changes.
+func handleAPIEvents(w http.ResponseWriter, r *http.Request, chatID int64,
+	user *User) {
+	if origin := r.Header.Get("Origin"); origin != "" &&
+		origin != config.Bot().APIOrigin {
+		originURL, err := url.Parse(origin)
+		if err != nil || originURL.Host != r.Host {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+	}
+	// Tables the user can not see are not found before upgrading.
+	_, err := getAPITable(chatID, user.ID)
+	if err != nil {
+		writeTableError(w, err)
+		return
+	}
+	conn, err := upgradeWebsocket(w, r)
+	if err != nil {
+		log.Println("Error:", err, "< handleAPIEvents")
+		return
+	}
+	defer conn.Close()
+	watcher := &apiWatcher{
+		UserID: user.ID,
+		Events: make(chan []byte, apiEventQueue),
+	}
+	watchTable(chatID, watcher)
+	defer unwatchTable(chatID, watcher)
+	// Start with the table as it is.
+	err = callTable(chatID, func() {
+		publishTable(chatID, getGame(chatID))
+	})
+	if err != nil {
+		log.Println("Error:", err, "< handleAPIEvents")
+		return
+	}
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			opcode, payload, err := conn.ReadFrame()
+			if err != nil || opcode == websocketClose {
+				conn.WriteFrame(websocketClose, nil)
+				return
+			}
+			if opcode == websocketPing {
+				conn.WriteFrame(websocketPong, payload)
+			}
+		}
+	}()
+	ping := time.NewTicker(apiPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case data := <-watcher.Events:
+			err = conn.WriteFrame(websocketText, data)
+		case <-ping.C:
+			err = conn.WriteFrame(websocketPing, nil)
+		case <-closed:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// The user of the token with the money in the wallet.
+func handleAPIMe(w http.ResponseWriter, r *http.Request, user *User) {
+	balance, err := store.Balance(user.ID)
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id":  user.ID,
+		"name":     user.FirstName,
+		"username": user.Username,
+		"balance":  balance,
+	})
+}
+
+// Sign up a user without Telegram, who gets an empty wallet and a token.
+func handleAPISignUp(w http.ResponseWriter, r *http.Request) {
+	if !config.Bot().APISignup {
+		writeAPIError(w, http.StatusForbidden,
+			"Signing up on the web is not enabled.")
+		return
+	}
+	request := &apiSignUpRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "Bad request.")
+		return
+	}
+	if !webNamePattern.MatchString(request.Name) {
+		writeAPIError(w, http.StatusBadRequest,
+			"Names have 3 to 20 letters, digits or _.")
+		return
+	}
+	username := webUsernamePrefix + strings.ToLower(request.Name)
+	userID, err := store.SignUp(username)
+	if err == ErrNameTaken {
+		writeAPIError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	err = store.SetDisplayName(userID, request.Name)
+	if err != nil {
+		log.Println("Error:", err, "< handleAPISignUp")
+	}
+	user := &User{ID: userID, FirstName: request.Name, Username: username}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id":  user.ID,
+		"name":     user.FirstName,
+		"username": user.Username,
+		"token":    newToken(user),
+	})
+}
+
+// A new token of the user, so web users keep their accounts by renewing
+// it before it expires.
+func handleAPIToken(w http.ResponseWriter, r *http.Request, user *User) {
+	writeJSON(w, http.StatusOK, map[string]string{"token": newToken(user)})
+}
+
+// Claim the daily money like /getmoney.
+func handleAPIGetMoney(w http.ResponseWriter, r *http.Request, user *User) {
+	if isShuttingDown() {
+		writeAPIError(w, http.StatusServiceUnavailable, "Shutting down.")
+		return
+	}
+	text, err := claimMoney(apiLanguage(user), user.ID)
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	balance, err := store.Balance(user.ID)
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"text":    text,
+		"balance": balance,
+	})
+}
+
+// apiHandler routes the API:
+//
+//	POST /api/signup
+//	POST /api/token
+//	POST /api/getmoney
+//	GET  /api/me
+//	GET  /api/tables
+//	GET  /api/tables/<chat>
+//	POST /api/tables/<chat>/actions
+//	GET  /api/tables/<chat>/events (WebSocket)
+type apiHandler struct {
+	Bot *Bot
+}
+
+func (h *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if config.Bot().APIOrigin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", config.Bot().APIOrigin)
+		w.Header().Set("Access-Control-Allow-Headers",
+			"Authorization, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+	}
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) == 2 && parts[1] == "signup" && r.Method == http.MethodPost {
+		handleAPISignUp(w, r)
+		return
+	}
+	// Every other call needs a token, so chats and their players are not
+	// shown to anyone.
+	user, err := apiUser(r)
+	if err != nil {
+		writeAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if len(parts) == 2 && r.Method == http.MethodPost {
+		switch parts[1] {
+		case "token":
+			handleAPIToken(w, r, user)
+			return
+		case "getmoney":
+			handleAPIGetMoney(w, r, user)
+			return
+		}
+	}
+	if len(parts) == 2 && parts[1] == "me" && r.Method == http.MethodGet {
+		handleAPIMe(w, r, user)
+		return
+	}
+	if len(parts) < 2 || parts[1] != "tables" {
+		writeAPIError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	if len(parts) == 2 {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed,
+				"Method not allowed.")
+			return
+		}
+		handleAPITables(w, r, user)
+		return
+	}
+	chatID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		handleAPITable(w, r, chatID, user)
+	case len(parts) == 4 && parts[3] == "events":
+		handleAPIEvents(w, r, chatID, user)
+	case len(parts) == 4 && parts[3] == "actions" &&
+		r.Method == http.MethodPost:
+		handleAPIAction(h.Bot, w, r, chatID, user)
+	default:
+		writeAPIError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+// Serve the API of web clients at APIListen.
+func runAPI(e *Bot) {
+	mux := http.NewServeMux()
+	mux.Handle("/api/", &apiHandler{Bot: e})
+	go func() {
+		err := http.ListenAndServe(config.Bot().APIListen, mux)
+		panic("Error: " + err.Error())
+	}()
+	log.Println("Serving the API at", config.Bot().APIListen)
+}
@@ -0,0 +1,252 @@
+package texas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magicae/texas-holdem-bot/config"
+	"github.com/magicae/texas-holdem-bot/telegramtest"
+)
+
+// A token of /web for the user.
+func apiToken(user *telegramtest.User) string {
+	return signToken(&apiClaims{
+		UserID:   user.ID,
+		Name:     user.FirstName,
+		Username: user.Username,
+		Expires:  time.Now().Add(time.Hour).Unix(),
+	})
+}
+
+// Call the API with the token and decode the answer, or the error, into v
+// unless it is nil. Returns the status.
+func callAPI(t *testing.T, method string, path string, token string,
+	body string, v interface{}) int {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	r := httptest.NewRequest(method, path, reader)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	(&apiHandler{Bot: e2eBot}).ServeHTTP(w, r)
+	if v != nil {
+		err := json.Unmarshal(w.Body.Bytes(), v)
+		if err != nil {
+			t.Fatalf("%s %s returns %q: %v", method, path, w.Body, err)
+		}
+	}
+	return w.Code
+}
+
+func TestAPITablesMethods(t *testing.T) {
+	token := apiToken(&telegramtest.User{ID: newID(), FirstName: "Pat"})
+	for _, method := range []string{http.MethodPost, http.MethodPut,
+		http.MethodDelete} {
+		for _, path := range []string{"/api/tables", "/api/tables/"} {
+			code := callAPI(t, method, path, token, "", nil)
+			if code != http.StatusMethodNotAllowed {
+				t.Errorf("%s %s returns %d, want %d", method, path, code,
+					http.StatusMethodNotAllowed)
+			}
+		}
+	}
+	code := callAPI(t, http.MethodGet, "/api/tables", token, "", nil)
+	if code != http.StatusOK {
+		t.Errorf("GET /api/tables returns %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestAPINeedsToken(t *testing.T) {
+	user := &telegramtest.User{ID: newID(), FirstName: "Quinn"}
+	token := apiToken(user)
+	expired := signToken(&apiClaims{UserID: user.ID,
+		Expires: time.Now().Add(-time.Minute).Unix()})
+	forged := strings.Replace(token, ".", "x.", 1)
+	for _, bad := range []string{"", expired, forged, "token"} {
+		for _, path := range []string{"/api/me", "/api/tables",
+			"/api/tables/-1"} {
+			code := callAPI(t, http.MethodGet, path, bad, "", nil)
+			if code != http.StatusUnauthorized {
+				t.Errorf("GET %s with %q returns %d, want %d", path, bad,
+					code, http.StatusUnauthorized)
+			}
+		}
+	}
+	me := map[string]interface{}{}
+	if code := callAPI(t, http.MethodGet, "/api/me", token, "",
+		&me); code != http.StatusOK {
+		t.Fatalf("GET /api/me returns %d", code)
+	}
+	if me["name"] != "Quinn" {
+		t.Errorf("GET /api/me returns %v", me)
+	}
+}
+
+func TestAPIHidesPrivateTables(t *testing.T) {
+	table := newE2ETable(t, "Nina")
+	stranger := apiToken(&telegramtest.User{ID: newID(), FirstName: "Omar"})
+	player := apiToken(table.users["Nina"])
+	table.say("Nina", "/new")
+	table.expect(table.chat.ID, "started a new game!")
+	path := "/api/tables/" + fmt.Sprint(table.chat.ID)
+	// Chats without a game do not get a table.
+	missing := -int64(newID())
+	code := callAPI(t, http.MethodGet, "/api/tables/"+fmt.Sprint(missing),
+		stranger, "", nil)
+	if code != http.StatusNotFound {
+		t.Errorf("GET of a chat without a game returns %d", code)
+	}
+	if hasTable(missing) {
+		t.Errorf("GET starts a table of a chat without a game")
+	}
+	if code := callAPI(t, http.MethodGet, path, stranger, "",
+		nil); code != http.StatusNotFound {
+		t.Errorf("Stranger gets the private table with %d", code)
+	}
+	if code := callAPI(t, http.MethodPost, path+"/actions", stranger,
+		`{"action": "join"}`, nil); code != http.StatusNotFound {
+		t.Errorf("Stranger joins the private table with %d", code)
+	}
+	list := []*apiTable{}
+	callAPI(t, http.MethodGet, "/api/tables", stranger, "", &list)
+	for _, listed := range list {
+		if listed.ChatID == table.chat.ID {
+			t.Errorf("Private table is listed to a stranger")
+		}
+	}
+	if code := callAPI(t, http.MethodGet, path, player, "",
+		nil); code != http.StatusOK {
+		t.Errorf("Player gets the table with %d", code)
+	}
+	table.say("Nina", "/public")
+	table.expect(table.chat.ID, "Nina made the table public.")
+	seen := &apiTable{}
+	if code := callAPI(t, http.MethodGet, path, stranger, "",
+		seen); code != http.StatusOK {
+		t.Fatalf("Stranger gets the public table with %d", code)
+	}
+	if len(seen.Actions) != 1 || seen.Actions[0].Action != "join" {
+		t.Errorf("Stranger can %v at the public table", seen.Actions)
+	}
+	table.say("Nina", "/leave")
+	table.expect(table.chat.ID, "Game ends!")
+	table.checkWallets()
+}
+
+// Whether the chat has a table.
+func hasTable(chatID int64) bool {
+	tablesMutex.Lock()
+	defer tablesMutex.Unlock()
+	return tables[chatID] != nil
+}
+
+func TestAPIWebPlayer(t *testing.T) {
+	config.UpdateBot(func(bot *config.BotConfig) {
+		bot.APISignup = true
+	})
+	defer config.UpdateBot(func(bot *config.BotConfig) {
+		bot.APISignup = false
+	})
+	table := newE2ETable(t, "Sam")
+	signed := map[string]interface{}{}
+	if code := callAPI(t, http.MethodPost, "/api/signup", "",
+		`{"name": "Rita"}`, &signed); code != http.StatusOK {
+		t.Fatalf("Signing up returns %d: %v", code, signed)
+	}
+	if code := callAPI(t, http.MethodPost, "/api/signup", "",
+		`{"name": "rita"}`, nil); code != http.StatusConflict {
+		t.Errorf("Signing up with a taken name returns %d", code)
+	}
+	rita := signed["token"].(string)
+	ritaID := int(signed["user_id"].(float64))
+	table.users["Rita"] = &telegramtest.User{ID: ritaID, FirstName: "Rita",
+		Username: "web_rita"}
+	// The wallet of the web starts empty, and gets the daily money.
+	claimed := map[string]interface{}{}
+	callAPI(t, http.MethodPost, "/api/getmoney", rita, "", &claimed)
+	if text, _ := claimed["text"].(string); !strings.Contains(text,
+		"Wow! You got $") {
+		t.Fatalf("Claiming money returns %v", claimed)
+	}
+	balance := int64(claimed["balance"].(float64))
+	_, err := store.Credit(ritaID, e2eMoney-balance,
+		LedgerEntry{Reason: LedgerGetMoney})
+	if err != nil {
+		t.Fatal(err)
+	}
+	credited += e2eMoney
+	table.say("Sam", "/new")
+	table.expect(table.chat.ID, "started a new game!")
+	table.say("Sam", "/public")
+	table.expect(table.chat.ID, "Sam made the table public.")
+	path := "/api/tables/" + fmt.Sprint(table.chat.ID)
+	if code := callAPI(t, http.MethodPost, path+"/actions", rita,
+		`{"action": "join"}`, nil); code != http.StatusOK {
+		t.Fatalf("Joining returns %d", code)
+	}
+	table.expect(table.chat.ID,
+		"Rita (@web_rita) bought 5000 chips and joined the game!")
+	table.say("Sam", "/startgame")
+	table.nextStatus()
+	tokens := map[string]string{
+		"Rita": rita,
+		"Sam":  apiToken(table.users["Sam"]),
+	}
+	// Everyone sees only the own hole cards.
+	for name, token := range tokens {
+		seen := &apiTable{}
+		callAPI(t, http.MethodGet, path, token, "", seen)
+		for _, seat := range seen.Seats {
+			if (seat.Name == name) != (len(seat.Cards) == 2) {
+				t.Errorf("%s sees the cards %v of %s", name, seat.Cards,
+					seat.Name)
+			}
+		}
+	}
+	actor := table.actor()
+	for name, token := range tokens {
+		if name == actor {
+			continue
+		}
+		refused := map[string]string{}
+		code := callAPI(t, http.MethodPost, path+"/actions", token,
+			`{"action": "fold"}`, &refused)
+		if code != http.StatusConflict ||
+			refused["error"] != "It is not your turn." {
+			t.Errorf("Folding out of turn returns %d: %v", code, refused)
+		}
+	}
+	if code := callAPI(t, http.MethodPost, path+"/actions", tokens[actor],
+		`{"action": "dance"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("Unknown action returns %d", code)
+	}
+	// The fold on the web ends the hand in the group.
+	if code := callAPI(t, http.MethodPost, path+"/actions", tokens[actor],
+		`{"action": "fold"}`, nil); code != http.StatusOK {
+		t.Fatalf("Folding returns %d", code)
+	}
+	table.nextStatus()
+	if !table.ended() {
+		t.Fatalf("Hand goes on after folding on the web:\n%s",
+			table.status.Text)
+	}
+	results := table.results()
+	if code := callAPI(t, http.MethodPost, path+"/actions", rita,
+		`{"action": "leave"}`, nil); code != http.StatusOK {
+		t.Errorf("Leaving returns %d", code)
+	}
+	table.expect(table.chat.ID, fmt.Sprintf("Bye! You took $%d back!",
+		results["Rita"]))
+	table.say("Sam", "/leave")
+	table.expect(table.chat.ID, "Game ends!")
+	table.checkWallets()
+}
@@ -0,0 +1,59 @@
+package texas
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+var ErrClaimed = errors.New("Already claimed.")
+
+// Location where daily bonus resets at midnight.
+var bonusLocation = time.UTC
+
+func loadBonusLocation() {
+	if config.Bot().Timezone == "" {
+		return
+	}
+	location, err := time.LoadLocation(config.Bot().Timezone)
+	if err != nil {
+		panic("Error: " + err.Error())
+	}
+	bonusLocation = location
+}
+
+// Get today and yesterday in the bonus timezone, and how long until the
+// next day.
+func getBonusDays(now time.Time) (string, string, time.Duration) {
+	now = now.In(bonusLocation)
+	today := now.Format("2006-01-02")
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	year, month, day := now.Date()
+	tomorrow := time.Date(year, month, day+1, 0, 0, 0, 0, bonusLocation)
+	return today, yesterday, tomorrow.Sub(now)
+}
+
+// Daily bonus grows by GetMoneyStreakBonus percent for every day in a row.
+func getStreakBonus(money int64, streak int64) int64 {
+	if streak > config.Bot().GetMoneyMaxStreak {
+		streak = config.Bot().GetMoneyMaxStreak
+	}
+	if streak < 1 {
+		streak = 1
+	}
+	return money * (100 + (streak-1)*config.Bot().GetMoneyStreakBonus) / 100
+}
+
+// Format a duration like "3h12m".
+func formatWait(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "1m"
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%dh%dm", d/time.Hour, d%time.Hour/time.Minute)
+}
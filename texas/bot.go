@@ -0,0 +1,136 @@
+package texas
+
+import (
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+	"gopkg.in/redis.v5"
+)
+
+func logger(e *Bot, update *Update) error {
+	log.Println("Resolve #", update.UpdateID)
+	if update.Message != nil {
+		log.Println("Incoming message:", update.Message)
+	}
+	return nil
+}
+
+func textMessageHandler(e *Bot, update *Update) error {
+	if update.Message != nil && !isShuttingDown() {
+		// Commands of a chat run one by one on its table.
+		return runOnTable(update.Message.Chat.ID, func() {
+			criticalTextMessageHandler(e, update.Message)
+		})
+	}
+	return nil
+}
+
+func criticalTextMessageHandler(e *Bot, message *Message) {
+	defer observeHandler("message", time.Now())
+	err := routeCommand(e, message)
+	if err != nil {
+		log.Println("Error:", err, "< criticalTextMessage")
+	}
+	err = saveGame(message.Chat.ID)
+	if err != nil {
+		log.Println("Error:", err, "< saveGame")
+	}
+}
+
+// Return chips left at tables by the last run to their owners, unless the
+// table has been restored.
+func refundEscrows() {
+	chatIDs, err := store.EscrowTables()
+	if err != nil {
+		panic("Error: " + err.Error())
+	}
+	for _, chatID := range chatIDs {
+		if getGame(chatID) != nil {
+			continue
+		}
+		refunds, err := store.Refund(chatID)
+		if err != nil {
+			log.Println("Error:", err, "< refundEscrows")
+			continue
+		}
+		for userID, chip := range refunds {
+			log.Println("Refund", chip, "to", userID, "from table", chatID)
+		}
+	}
+}
+
+// Load the configuration again on SIGHUP.
+func reloadOnHangup(configPath string) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		err := config.Reload(configPath)
+		if err != nil {
+			log.Println("Error:", err, "< reloadOnHangup")
+			continue
+		}
+		loadBonusLocation()
+		log.Println("Reloaded", configPath)
+	}
+}
+
+// Run the bot with the configuration file at configPath until it is
+// stopped. Wallets are kept in memory instead of Redis if memory is set.
+func Run(configPath string, memory bool) {
+	rand.Seed(time.Now().UnixNano())
+	config.Languages = getLanguages()
+	config.CardStyles = cardStyles
+	config.CardThemes = getCardThemes()
+	err := config.Load(configPath)
+	if err != nil {
+		panic("Error: " + err.Error())
+	}
+	go reloadOnHangup(configPath)
+	loadBonusLocation()
+	if memory {
+		store = NewMemoryStore()
+	} else {
+		store = NewRedisStore(redis.NewClient(config.Database()))
+	}
+	if config.Bot().MetricsListen != "" {
+		runMetrics()
+	}
+	e := NewBot(config.Bot().Token)
+	me, err := e.GetMe()
+	if err != nil {
+		panic("Error: " + err.Error())
+	} else {
+		config.UpdateBot(func(bot *config.BotConfig) {
+			bot.Username = me.Username
+			bot.ID = me.ID
+		})
+		log.Println("Bot info:", me)
+	}
+	loadGames(e)
+	refundEscrows()
+	if config.Bot().APIListen != "" {
+		runAPI(e)
+	}
+	if config.Bot().SeasonDays > 0 {
+		go runSeasons(e)
+	}
+	if config.Bot().WebhookURL != "" {
+		runWebhook(e)
+		return
+	}
+	for _, handler := range handlers {
+		e.AddHandler(handler)
+	}
+	go func() {
+		waitForSignal()
+		shutdown(e)
+		os.Exit(0)
+	}()
+	e.RunLongPolling()
+}
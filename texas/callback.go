@@ -0,0 +1,111 @@
+package texas
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+)
+
+func callbackQueryHandler(e *Bot, update *Update) error {
+	query := update.CallbackQuery
+	if query != nil && query.Message != nil && !isShuttingDown() {
+		// Buttons of a table run on it like commands.
+		return runOnTable(query.Message.Chat.ID, func() {
+			criticalCallbackQueryHandler(e, query)
+		})
+	}
+	return nil
+}
+
+func criticalCallbackQueryHandler(e *Bot, query *CallbackQuery) {
+	defer observeHandler("callback_query", time.Now())
+	text, err := handleTableButton(e, query)
+	if err != nil {
+		log.Println("Error:", err, "< criticalCallbackQuery")
+	}
+	// Always answer, or the button keeps loading.
+	_, err = e.AnswerCallbackQuery(&AnswerCallbackQueryRequest{
+		CallbackQueryID: query.ID,
+		Text:            text,
+	})
+	if err != nil {
+		log.Println("Error:", err, "< answerCallbackQuery")
+	}
+	err = saveGame(query.Message.Chat.ID)
+	if err != nil {
+		log.Println("Error:", err, "< saveGame")
+	}
+}
+
+// Take the action of a button on the table message. Returns the text to show
+// to the user who pressed it.
+func handleTableButton(e *Bot, query *CallbackQuery) (string, error) {
+	chat := query.Message.Chat
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	if game == nil || game.Round == nil ||
+		game.Round.StatusMessageID != query.Message.MessageID {
+		return tr(lang, "This table message is outdated."), nil
+	}
+	return takeTableAction(e, game, chat, query.From, query.Data)
+}
+
+// Take an action of the table buttons: startgame, leave, check, call, fold,
+// allin or raise:<total>. Returns the text to show to the user if the action
+// is refused.
+func takeTableAction(e *Bot, game *Texas, chat *Chat, user *User,
+	action string) (string, error) {
+	lang := getLanguage(chat.ID)
+	seated := false
+	for i := 0; i < 10; i++ {
+		if game.Players[i] != nil && game.Players[i].UserID == user.ID {
+			seated = true
+			break
+		}
+	}
+	if !seated {
+		return tr(lang, "You need to /join game at first."), nil
+	}
+	switch action {
+	case "startgame":
+		err := game.StartRound()
+		if err != nil {
+			return tr(lang, err.Error()), nil
+		}
+		return "", game.MoveOn()
+	case "leave":
+		return "", handleLeave(e, 0, chat, user)
+	}
+	if game.Paused {
+		return tr(lang, ErrTablePaused.Error()), nil
+	}
+	if game.Round == nil || game.Round.Stage >= End ||
+		game.Players[game.Round.ActorIndex].UserID != user.ID {
+		return tr(lang, "It is not your turn."), nil
+	}
+	var err error
+	switch {
+	case action == "check":
+		err = game.Check(user.ID)
+	case action == "call":
+		err = game.Call(user.ID)
+	case action == "fold":
+		err = game.Fold(user.ID)
+	case action == "allin":
+		err = game.AllIn(user.ID)
+	case strings.HasPrefix(action, "raise:"):
+		var n int64
+		n, err = strconv.ParseInt(strings.TrimPrefix(action, "raise:"),
+			10, 64)
+		if err == nil {
+			err = game.RaiseTo(user.ID, n)
+		}
+	}
+	if err != nil {
+		return tr(lang, err.Error()), nil
+	}
+	return "", nil
+}
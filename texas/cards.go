@@ -0,0 +1,181 @@
+package texas
+
+import (
+	"log"
+	"strings"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// Card styles. Images and stickers show cards as pictures and emoji in
+// texts, the others show cards in texts only.
+const (
+	CardsImage     = "image"
+	CardsSticker   = "sticker"
+	CardsEmoji     = "emoji"
+	CardsASCII     = "ascii"
+	CardsFourColor = "four-color"
+	CardsSpoken    = "spoken"
+)
+
+// Card styles in the order of /cards.
+var cardStyles = []string{CardsImage, CardsSticker, CardsEmoji, CardsASCII,
+	CardsFourColor, CardsSpoken}
+
+var cardStyleUsages = map[string]string{
+	CardsImage:     "pictures of the table and cards",
+	CardsSticker:   "card stickers",
+	CardsEmoji:     "text with suit emoji",
+	CardsASCII:     "plain text like As Kd",
+	CardsFourColor: "text with four suit colors",
+	CardsSpoken:    "words for screen readers, like Ace of spades",
+}
+
+// Colors of diamonds, hearts, clubs and spades in four-color texts.
+var fourColorSuitTexts = [4]string{"🔵", "🔴", "🟢", "⚫"}
+
+var spokenRanks = [15]string{"", "", "Two", "Three", "Four", "Five", "Six",
+	"Seven", "Eight", "Nine", "Ten", "Jack", "Queen", "King", "Ace"}
+var spokenSuits = [4]string{"diamonds", "hearts", "clubs", "spades"}
+
+// Card style of a chat. The private chat of a user has the ID of the user.
+func getCardStyle(chatID int64) string {
+	style, err := store.CardStyle(chatID)
+	if err == nil && cardStyleUsages[style] != "" {
+		return style
+	}
+	if cardStyleUsages[config.Bot().CardStyle] != "" {
+		return config.Bot().CardStyle
+	}
+	return CardsImage
+}
+
+// Text of the card in the style, or of an unknown card if card is nil.
+func getCardText(style string, lang string, card *PokerCard) string {
+	switch style {
+	case CardsASCII:
+		if card == nil {
+			return "??"
+		}
+		return getPokerNotation(card)
+	case CardsFourColor:
+		if card == nil {
+			return "？"
+		}
+		return fourColorSuitTexts[card.Suit] + config.PokerRankTexts[card.Rank]
+	case CardsSpoken:
+		if card == nil {
+			return tr(lang, "unknown card")
+		}
+		return trf(lang, "%s of %s", tr(lang, spokenRanks[card.Rank]),
+			tr(lang, spokenSuits[card.Suit]))
+	}
+	if card == nil {
+		return "？"
+	}
+	return getPokerText(card)
+}
+
+// Text of the cards in the style.
+func getCardsText(style string, lang string, cards ...*PokerCard) string {
+	texts := make([]string, len(cards))
+	for i, card := range cards {
+		texts[i] = getCardText(style, lang, card)
+	}
+	if style == CardsSpoken {
+		return strings.Join(texts, ", ")
+	}
+	return strings.Join(texts, " ")
+}
+
+// Send the cards as stickers.
+func sendStickers(e *Bot, chatID int64, cards ...*PokerCard) {
+	for _, card := range cards {
+		_, err := e.SendSticker(&SendStickerRequest{
+			ChatID:  chatID,
+			Sticker: getPokerSticker(card),
+		})
+		if err != nil {
+			log.Println("Error:", err, "< sendStickers")
+		}
+	}
+}
+
+func handleCards(e *Bot, id int, chat *Chat, user *User, args string) error {
+	lang := getLanguage(chat.ID)
+	text := ""
+	args = strings.ToLower(args)
+	if cardStyleUsages[args] != "" {
+		err := store.SetCardStyle(chat.ID, args)
+		if err != nil {
+			return err
+		}
+		text = trf(lang, "Cards are shown as %s.",
+			tr(lang, cardStyleUsages[args]))
+	} else {
+		style := getCardStyle(chat.ID)
+		text = trf(lang, "Cards: %s\nUse /cards <style> to change it:", style)
+		for _, style := range cardStyles {
+			text += "\n" + style + " - " + tr(lang, cardStyleUsages[style])
+		}
+	}
+	_, err := e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	})
+	return err
+}
+
+// Send the new community cards to the group in its style. Text styles
+// show them in the table message only.
+func (t *Texas) SendBoard(cards ...*PokerCard) {
+	switch getCardStyle(t.ChatID) {
+	case CardsImage:
+		t.SendTableImage(t.ChatID)
+	case CardsSticker:
+		sendStickers(t.Bot, t.ChatID, cards...)
+	}
+}
+
+// Send the hole cards of the player with the board to the private chat in
+// the style of the player.
+func (t *Texas) SendHand(chatID int64, i int, caption string) {
+	// Players without Telegram see their cards on the web.
+	if chatID == 0 {
+		return
+	}
+	style := getCardStyle(chatID)
+	lang := getLanguage(chatID)
+	hole := t.Round.PlayerCards[i]
+	board := make([]*PokerCard, 0, 5)
+	for _, card := range t.Round.CommunityCards {
+		if card != nil {
+			board = append(board, card)
+		}
+	}
+	text := caption + "\n" + getCardsText(style, lang, hole[:]...)
+	if len(board) > 0 {
+		text += "\n" + tr(lang, "Community cards:") + " " +
+			getCardsText(style, lang, board...)
+	}
+	switch style {
+	case CardsImage:
+		data, err := renderHand(hole, t.Round.CommunityCards)
+		sendImage(t.Bot, chatID, data, err, caption, text)
+		return
+	case CardsSticker:
+		if len(board) == 0 {
+			text = caption
+			defer sendStickers(t.Bot, chatID, hole[:]...)
+		}
+	}
+	_, err := t.Bot.SendMessage(&SendMessageRequest{
+		ChatID: chatID,
+		Text:   text,
+	})
+	if err != nil {
+		log.Println("Error:", err, "< SendHand")
+	}
+}
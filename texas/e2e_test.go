@@ -0,0 +1,405 @@
+package texas
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+	"github.com/magicae/texas-holdem-bot/telegramtest"
+	"gopkg.in/redis.v5"
+)
+
+// End-to-end tests play hands through the long polling bot against a fake of
+// the Bot API. Wallets are in memory, or in the database 15 of the Redis at
+// TEXAS_TEST_REDIS, which is flushed.
+
+// How long to wait for an answer of the bot.
+const e2eTimeout = 5 * time.Second
+
+// Everyone starts with it.
+const e2eMoney = 10000
+
+var (
+	server *telegramtest.Server
+	// The bot polling the server.
+	e2eBot *Bot
+	// Money given to every player so far.
+	credited int64
+	// The last ID of a group or user.
+	lastID int
+)
+
+var (
+	waitingPattern = regexp.MustCompile(`Waiting .+ \(@(\w+)\)\.\.\.$`)
+	resultPattern  = regexp.MustCompile(`(?m)^\[\d+\] (\S+) .*-> (\d+) chips?\.$`)
+)
+
+func TestMain(m *testing.M) {
+	server = telegramtest.NewServer()
+	http.DefaultTransport = server.Transport(http.DefaultTransport)
+	config.UpdateBot(func(bot *config.BotConfig) {
+		bot.CardStyle = CardsASCII
+		bot.Language = "en"
+		bot.APISecret = "secret of the e2e tests"
+	})
+	if addr := os.Getenv("TEXAS_TEST_REDIS"); addr != "" {
+		client := redis.NewClient(&redis.Options{Addr: addr, DB: 15})
+		err := client.FlushDb().Err()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		store = NewRedisStore(client)
+	} else {
+		store = NewMemoryStore()
+	}
+	e := NewBot("e2e")
+	e2eBot = e
+	me, err := e.GetMe()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	config.UpdateBot(func(bot *config.BotConfig) {
+		bot.ID = me.ID
+		bot.Username = me.Username
+	})
+	for _, handler := range handlers {
+		e.AddHandler(handler)
+	}
+	go e.RunLongPolling()
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+// A group with scripted users.
+type e2eTable struct {
+	t     *testing.T
+	chat  *telegramtest.Chat
+	users map[string]*telegramtest.User
+	// Index of the first call of the table, and of the first call not
+	// waited for yet.
+	first int
+	next  int
+	// Calls already matched.
+	seen map[*telegramtest.Sent]bool
+	// The last status of the hand.
+	status *telegramtest.Sent
+}
+
+// Return an ID nobody has, so tests can run again in the same wallets.
+func newID() int {
+	lastID++
+	return lastID
+}
+
+// Create a group with the users, who are registered and have e2eMoney in
+// their wallets.
+func newE2ETable(t *testing.T, names ...string) *e2eTable {
+	table := &e2eTable{
+		t: t,
+		chat: &telegramtest.Chat{ID: -int64(newID()), Type: "group",
+			Title: t.Name()},
+		users: map[string]*telegramtest.User{},
+		first: len(server.Sent()),
+		seen:  map[*telegramtest.Sent]bool{},
+	}
+	table.next = table.first
+	for _, name := range names {
+		user := &telegramtest.User{
+			ID:        newID(),
+			FirstName: name,
+			Username:  strings.ToLower(name),
+		}
+		table.users[name] = user
+		private := &telegramtest.Chat{ID: int64(user.ID), Type: "private"}
+		server.SendMessage(private, user, "/start")
+		table.expect(private.ID, "You are registered in this bot!")
+		_, err := store.Credit(user.ID, e2eMoney,
+			LedgerEntry{Reason: LedgerGetMoney})
+		if err != nil {
+			t.Fatal(err)
+		}
+		credited += e2eMoney
+	}
+	return table
+}
+
+// Wait for a call of the bot from the nth to the chat which matches and has
+// not been matched before. Answers of buttons are to the chat 0.
+func (table *e2eTable) find(n int, chatID int64,
+	match func(*telegramtest.Sent) bool) (*telegramtest.Sent, int) {
+	sent, n := server.Wait(n, e2eTimeout,
+		func(sent *telegramtest.Sent) bool {
+			return !table.seen[sent] && sent.ChatID == chatID && match(sent)
+		})
+	if sent != nil {
+		table.seen[sent] = true
+	}
+	return sent, n
+}
+
+// Wait for the next call to the chat which matches, skipping the ones
+// before it.
+func (table *e2eTable) wait(chatID int64,
+	match func(*telegramtest.Sent) bool) *telegramtest.Sent {
+	sent, n := table.find(table.next, chatID, match)
+	if sent == nil {
+		table.t.Fatalf("Timed out waiting for the bot in %d", chatID)
+	}
+	table.next = n + 1
+	return sent
+}
+
+// Wait for a message to the chat with the text, in any order with the
+// other expected ones.
+func (table *e2eTable) expect(chatID int64, text string) *telegramtest.Sent {
+	sent, _ := table.find(table.first, chatID, func(sent *telegramtest.Sent) bool {
+		return strings.Contains(sent.Text, text)
+	})
+	if sent == nil {
+		table.t.Fatalf("Timed out waiting for %q in %d", text, chatID)
+	}
+	return sent
+}
+
+// Send the text to the group as the user.
+func (table *e2eTable) say(name string, text string) {
+	server.SendMessage(table.chat, table.users[name], text)
+}
+
+// Wait for the next status of the hand, with the buttons.
+func (table *e2eTable) nextStatus() *telegramtest.Sent {
+	table.status = table.wait(table.chat.ID,
+		func(sent *telegramtest.Sent) bool {
+			return len(sent.Buttons) > 0
+		})
+	return table.status
+}
+
+// Whether the status has a button of the data.
+func hasButton(status *telegramtest.Sent, data string) bool {
+	for _, button := range status.Buttons {
+		if button.Data == data {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the hand has ended.
+func (table *e2eTable) ended() bool {
+	return hasButton(table.status, "startgame")
+}
+
+// Name of the user the status waits for.
+func (table *e2eTable) actor() string {
+	match := waitingPattern.FindStringSubmatch(table.status.Text)
+	if match == nil {
+		table.t.Fatalf("Nobody to act in status:\n%s", table.status.Text)
+	}
+	for name, user := range table.users {
+		if user.Username == match[1] {
+			return name
+		}
+	}
+	table.t.Fatalf("Unknown actor @%s", match[1])
+	return ""
+}
+
+// Chips of everyone after the hand by the final status.
+func (table *e2eTable) results() map[string]int64 {
+	results := map[string]int64{}
+	for _, match := range resultPattern.FindAllStringSubmatch(
+		table.status.Text, -1) {
+		chip, _ := strconv.ParseInt(match[2], 10, 64)
+		results[match[1]] = chip
+	}
+	if len(results) != len(table.users) {
+		table.t.Fatalf("Results of %d players in status:\n%s",
+			len(table.users), table.status.Text)
+	}
+	return results
+}
+
+// Let everyone leave with the chips and check their wallets get them. The
+// game ends with the last one.
+func (table *e2eTable) leave(results map[string]int64) {
+	left := 0
+	for name, chip := range results {
+		table.say(name, "/leave")
+		text := fmt.Sprintf("Bye! You took $%d back!", chip)
+		if left++; left == len(results) {
+			text += " Game ends!"
+		}
+		table.expect(table.chat.ID, text)
+		balance, err := store.Balance(table.users[name].ID)
+		if err != nil {
+			table.t.Fatal(err)
+		}
+		if want := e2eMoney - config.Bot().MaxBuyIn + chip; balance != want {
+			table.t.Errorf("%s has $%d, want $%d", name, balance, want)
+		}
+	}
+}
+
+// Check no chips got lost and every ledger matches its wallet.
+func (table *e2eTable) checkWallets() {
+	sum := int64(0)
+	for name, user := range table.users {
+		balance, err := store.Balance(user.ID)
+		if err != nil {
+			table.t.Fatal(err)
+		}
+		sum += balance
+		text, err := auditLedger("en", user.ID)
+		if err != nil {
+			table.t.Fatal(err)
+		}
+		if !strings.Contains(text, "Ledger matches the balance.") {
+			table.t.Errorf("Ledger of %s:\n%s", name, text)
+		}
+	}
+	if want := int64(len(table.users)) * e2eMoney; sum != want {
+		table.t.Errorf("Wallets have $%d, want $%d", sum, want)
+	}
+	total, err := store.TotalBalance()
+	if err != nil {
+		table.t.Fatal(err)
+	}
+	if total != credited {
+		table.t.Errorf("Every wallet has $%d, want $%d", total, credited)
+	}
+}
+
+// Start a game of everyone and deal a hand.
+func (table *e2eTable) start(owner string) {
+	table.say(owner, "/new")
+	table.expect(table.chat.ID, "started a new game!")
+	for name := range table.users {
+		if name != owner {
+			table.say(name, "/join")
+			table.expect(table.chat.ID, "joined the game!")
+		}
+	}
+	table.say(owner, "/startgame")
+	for _, user := range table.users {
+		table.expect(int64(user.ID),
+			"New round starts! Dealing for you. Good luck!")
+	}
+	table.nextStatus()
+	if !strings.Contains(table.status.Text, "- Preflop - Pot: 150") {
+		table.t.Errorf("Hand starts with status:\n%s", table.status.Text)
+	}
+}
+
+func TestE2EShowdown(t *testing.T) {
+	table := newE2ETable(t, "Alice", "Bob", "Carol")
+	table.start("Alice")
+	raised := false
+	for !table.ended() {
+		command := "/call"
+		switch {
+		case !raised:
+			command = "/raise 200"
+			raised = true
+		case hasButton(table.status, "check"):
+			command = "/check"
+		}
+		table.say(table.actor(), command)
+		table.nextStatus()
+	}
+	showdown := table.expect(table.chat.ID, "= SHOWDOWN =")
+	for name := range table.users {
+		if !strings.Contains(showdown.Text, name) {
+			t.Errorf("%s is not at showdown:\n%s", name, showdown.Text)
+		}
+	}
+	if !strings.Contains(table.status.Text, "WIN +") {
+		t.Errorf("Nobody wins:\n%s", table.status.Text)
+	}
+	results := table.results()
+	sum := int64(0)
+	for _, chip := range results {
+		sum += chip
+	}
+	if want := int64(len(table.users)) * config.Bot().MaxBuyIn; sum != want {
+		t.Errorf("Players have %d chips, want %d", sum, want)
+	}
+	table.leave(results)
+	table.checkWallets()
+}
+
+func TestE2EFoldByButton(t *testing.T) {
+	table := newE2ETable(t, "Dave", "Erin")
+	table.start("Dave")
+	folder := table.actor()
+	if !hasButton(table.status, "fold") {
+		t.Fatalf("No button to fold:\n%s", table.status.Text)
+	}
+	server.PressButton(table.chat, table.users[folder],
+		table.status.MessageID, "fold")
+	table.nextStatus()
+	if table.status.Method != "editMessageText" {
+		t.Errorf("Status is sent again with %s", table.status.Method)
+	}
+	if !table.ended() {
+		t.Fatalf("Hand goes on after folding:\n%s", table.status.Text)
+	}
+	results := table.results()
+	for name, chip := range results {
+		if name == folder {
+			continue
+		}
+		// The folder posted the small blind heads-up.
+		if want := config.Bot().MaxBuyIn + config.Bot().SmallBlind; chip != want {
+			t.Errorf("%s has %d chips, want %d", name, chip, want)
+		}
+	}
+	table.leave(results)
+	table.checkWallets()
+}
+
+func TestE2ERefusals(t *testing.T) {
+	table := newE2ETable(t, "Frank", "Grace")
+	stranger := &telegramtest.User{ID: newID(), FirstName: "Heidi",
+		Username: "heidi"}
+	table.say("Frank", "/join")
+	table.expect(table.chat.ID, "You need to /new game first!")
+	server.SendMessage(table.chat, stranger, "/new")
+	table.expect(table.chat.ID, "Failed to start a new game.")
+	table.say("Frank", "/new")
+	table.expect(table.chat.ID, "started a new game!")
+	table.say("Frank", "/startgame")
+	table.expect(table.chat.ID, "Not enough players to start a new round.")
+	table.say("Grace", "/join")
+	table.expect(table.chat.ID, "joined the game!")
+	table.say("Grace", "/startgame")
+	table.nextStatus()
+	actor := table.actor()
+	for name := range table.users {
+		if name != actor {
+			server.PressButton(table.chat, table.users[name],
+				table.status.MessageID, "fold")
+			table.expect(0, "It is not your turn.")
+		}
+	}
+	// Leaving folds, so the other one takes the blinds.
+	table.say(actor, "/leave")
+	table.expect(table.chat.ID, "Bye! You took $")
+	for name := range table.users {
+		if name != actor {
+			table.say(name, "/leave")
+			table.expect(table.chat.ID, "Game ends!")
+		}
+	}
+	table.checkWallets()
+}
@@ -0,0 +1,542 @@
+package texas
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+func handlePrivateStart(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	err := store.Register(user.ID, chat.ID)
+	if err != nil {
+		return err
+	}
+	err = store.SetUsername(user.ID, user.Username)
+	if err != nil {
+		return err
+	}
+	body := &SendMessageRequest{
+		ChatID: chat.ID,
+		Text:   tr(lang, "You are registered in this bot! Lets start a game in group."),
+	}
+	_, err = e.SendMessage(body)
+	return err
+}
+
+func handleNewGame(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	// The game already started.
+	if getGame(chat.ID) != nil {
+		body := &SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             tr(lang, "Texas Hold'em has already started.\n/join"),
+			ReplyToMessageID: id,
+			ReplyMarkup: &ReplyKeyboardMarkup{
+				Keyboard:        [][]*KeyboardButton{config.Bot().OutButtons},
+				ResizeKeyboard:  true,
+				OneTimeKeyboard: true,
+				Selective:       true,
+			},
+		}
+		_, err := e.SendMessage(body)
+		return err
+	}
+	// Start a new game.
+	game := NewTexas(e, chat.ID, config.Bot().MaxBuyIn)
+	game.Owner = user.ID
+	// Add the beginner into it.
+	chip, err := game.AddUser(user)
+	text := ""
+	var markup *ReplyKeyboardMarkup
+	if err != nil {
+		text = tr(lang, "Failed to start a new game.") + " " +
+			tr(lang, err.Error())
+	} else {
+		setGame(chat.ID, game)
+		text = trn(lang, chip, "%s bought %d chip and started a new game!\n"+
+			"/join us to play Texas Hold'em together!",
+			"%s bought %d chips and started a new game!\n"+
+				"/join us to play Texas Hold'em together!",
+			getUserDisplayName(user), chip)
+		markup = &ReplyKeyboardMarkup{
+			Keyboard:        [][]*KeyboardButton{config.Bot().OutButtons},
+			ResizeKeyboard:  true,
+			OneTimeKeyboard: true,
+			Selective:       false,
+		}
+	}
+	// Start game success.
+	body := &SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+		ReplyMarkup:      markup,
+	}
+	_, err = e.SendMessage(body)
+	return err
+}
+
+func handleJoin(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	// Game is not ready.
+	if game == nil {
+		body := &SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             tr(lang, "You need to /new game first!"),
+			ReplyToMessageID: id,
+			ReplyMarkup: &ReplyKeyboardMarkup{
+				Keyboard: [][]*KeyboardButton{
+					[]*KeyboardButton{
+						&KeyboardButton{Text: "/new"},
+					},
+				},
+				ResizeKeyboard:  true,
+				OneTimeKeyboard: true,
+				Selective:       true,
+			},
+		}
+		_, err := e.SendMessage(body)
+		return err
+	}
+	// Join game.
+	chip, err := game.AddUser(user)
+	text := ""
+	var markup *ReplyKeyboardMarkup
+	if err != nil {
+		text = tr(lang, "Failed to join game.") + " " + tr(lang, err.Error())
+	} else {
+		text = trn(lang, chip, "%s (@%s) bought %d chip and joined the game!",
+			"%s (@%s) bought %d chips and joined the game!",
+			getUserDisplayName(user), user.Username, chip)
+		markup = &ReplyKeyboardMarkup{
+			Keyboard:        [][]*KeyboardButton{config.Bot().InGameButtons},
+			ResizeKeyboard:  true,
+			OneTimeKeyboard: true,
+			Selective:       true,
+		}
+	}
+	// Join game successfully.
+	body := &SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+		ReplyMarkup:      markup,
+	}
+	_, err = e.SendMessage(body)
+	return err
+}
+
+func handleList(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	if game == nil {
+		body := &SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             tr(lang, "Game is not ready."),
+			ReplyToMessageID: id,
+		}
+		_, err := e.SendMessage(body)
+		return err
+	}
+	// List user.
+	text := ""
+	count := 0
+	for i := 0; i < 10; i++ {
+		if game.Players[i] != nil {
+			count++
+			text += fmt.Sprintf("[%d] %s - %d\n", count,
+				game.Players[i].DisplayName, game.Players[i].Chip)
+		}
+	}
+	text = trf(lang, "Texas Hold'em Players (%d / 10)", count) + "\n" + text
+	body := &SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	}
+	_, err := e.SendMessage(body)
+	return err
+}
+
+func handleLeave(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	if game == nil {
+		body := &SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             tr(lang, "Game is not ready."),
+			ReplyToMessageID: id,
+		}
+		_, err := e.SendMessage(body)
+		return err
+	}
+	chip, err := leaveGame(game, user)
+	text := ""
+	if err != nil {
+		text = tr(lang, err.Error())
+	} else {
+		text = trf(lang, "Bye! You took $%d back!", chip)
+		if game.CountUser() == 0 {
+			text += " " + tr(lang, "Game ends!")
+		}
+	}
+	body := &SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	}
+	_, err = e.SendMessage(body)
+	return err
+}
+
+// Fold the user if the round is running and take the user's chips back. The
+// game ends if nobody is left.
+func leaveGame(game *Texas, user *User) (int64, error) {
+	if game.Round != nil && game.Round.Stage < End {
+		// Try fold.
+		game.Fold(user.ID)
+		game.GetOut(user.ID)
+	}
+	chip, err := game.RemoveUser(user)
+	if err == nil && game.CountUser() == 0 {
+		setGame(game.ChatID, nil)
+	}
+	return chip, err
+}
+
+func handleStartRound(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	if game == nil {
+		body := &SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             tr(lang, "Game is not ready."),
+			ReplyToMessageID: id,
+		}
+		_, err := e.SendMessage(body)
+		return err
+	}
+	joined := false
+	for i := 0; i < 10; i++ {
+		if game.Players[i] != nil && game.Players[i].UserID == user.ID {
+			joined = true
+			break
+		}
+	}
+	if !joined {
+		body := &SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             tr(lang, "You need to /join game at first."),
+			ReplyToMessageID: id,
+		}
+		_, err := e.SendMessage(body)
+		return err
+	}
+	err := game.StartRound()
+	if err != nil {
+		body := &SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             tr(lang, err.Error()),
+			ReplyToMessageID: id,
+		}
+		_, err := e.SendMessage(body)
+		return err
+	}
+	return game.MoveOn()
+}
+
+func handleFold(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	if game != nil && game.Round != nil && game.Round.Stage < End &&
+		game.Players[game.Round.ActorIndex].UserID == user.ID {
+		err := game.Fold(user.ID)
+		if err != nil {
+			body := &SendMessageRequest{
+				ChatID:           chat.ID,
+				Text:             tr(lang, err.Error()),
+				ReplyToMessageID: id,
+			}
+			_, err := e.SendMessage(body)
+			return err
+		}
+	}
+	return nil
+}
+
+func handleCall(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	if game != nil && game.Round != nil && game.Round.Stage < End &&
+		game.Players[game.Round.ActorIndex].UserID == user.ID {
+		err := game.Call(user.ID)
+		if err != nil {
+			body := &SendMessageRequest{
+				ChatID:           chat.ID,
+				Text:             tr(lang, err.Error()),
+				ReplyToMessageID: id,
+			}
+			_, err := e.SendMessage(body)
+			return err
+		}
+	}
+	return nil
+}
+
+func handleCheck(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	if game != nil && game.Round != nil && game.Round.Stage < End &&
+		game.Players[game.Round.ActorIndex].UserID == user.ID {
+		err := game.Check(user.ID)
+		if err != nil {
+			body := &SendMessageRequest{
+				ChatID:           chat.ID,
+				Text:             tr(lang, err.Error()),
+				ReplyToMessageID: id,
+			}
+			_, err := e.SendMessage(body)
+			return err
+		}
+	}
+	return nil
+}
+
+func handleRaise(e *Bot, id int, chat *Chat, user *User) error {
+	game := getGame(chat.ID)
+	if game != nil && game.Round != nil && game.Round.Stage < End &&
+		game.Players[game.Round.ActorIndex].UserID == user.ID {
+		// Post the table again with the raise sizes at the bottom.
+		game.Round.StatusMessageID = 0
+		return game.ShowStatus()
+	}
+	return nil
+}
+
+func handleRaiseN(e *Bot, n int64, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	if game != nil && game.Round != nil && game.Round.Stage < End &&
+		game.Players[game.Round.ActorIndex].UserID == user.ID {
+		err := game.Raise(user.ID, n)
+		if err != nil {
+			body := &SendMessageRequest{
+				ChatID:           chat.ID,
+				Text:             tr(lang, err.Error()),
+				ReplyToMessageID: id,
+			}
+			_, err := e.SendMessage(body)
+			return err
+		}
+	}
+	return nil
+}
+
+func handleRaiseTo(e *Bot, n int64, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	if game != nil && game.Round != nil && game.Round.Stage < End &&
+		game.Players[game.Round.ActorIndex].UserID == user.ID {
+		err := game.RaiseTo(user.ID, n)
+		if err != nil {
+			body := &SendMessageRequest{
+				ChatID:           chat.ID,
+				Text:             tr(lang, err.Error()),
+				ReplyToMessageID: id,
+			}
+			_, err := e.SendMessage(body)
+			return err
+		}
+	}
+	return nil
+}
+
+func handlePot(e *Bot, id int, chat *Chat, user *User) error {
+	game := getGame(chat.ID)
+	if game != nil && game.Round != nil && game.Round.Stage < End &&
+		game.Players[game.Round.ActorIndex].UserID == user.ID {
+		return handleRaiseTo(e, game.PotRaiseTo(4), id, chat, user)
+	}
+	return nil
+}
+
+func handleAllIn(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	game := getGame(chat.ID)
+	if game != nil && game.Round != nil && game.Round.Stage < End &&
+		game.Players[game.Round.ActorIndex].UserID == user.ID {
+		err := game.AllIn(user.ID)
+		if err != nil {
+			body := &SendMessageRequest{
+				ChatID:           chat.ID,
+				Text:             tr(lang, err.Error()),
+				ReplyToMessageID: id,
+			}
+			_, err := e.SendMessage(body)
+			return err
+		}
+	}
+	return nil
+}
+
+func handleGetMoney(e *Bot, id int, chat *Chat, user *User) error {
+	text, err := claimMoney(getLanguage(chat.ID), user.ID)
+	if err != nil {
+		return err
+	}
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	})
+	return err
+}
+
+// Claim the daily money, or the relief of an empty wallet, for the user.
+// Returns what the user gets.
+func claimMoney(lang string, userID int) (string, error) {
+	today, yesterday, wait := getBonusDays(time.Now())
+	streak, err := store.ClaimDaily(userID, today, yesterday)
+	if err == nil {
+		money := config.Bot().GetMoneyBase + rand.Int63n(config.Bot().GetMoneyBonus)
+		money = getStreakBonus(money, streak)
+		totalMoney, err := store.Credit(userID, money,
+			LedgerEntry{Reason: LedgerGetMoney})
+		if err != nil {
+			return "", err
+		}
+		text := trf(lang, "Wow! You got $%d and you have $%d now!", money,
+			totalMoney)
+		if streak > 1 {
+			text += " " + trf(lang, "%d days in a row!", streak)
+		}
+		return text + "\n" + trf(lang, "Next claim in %s.",
+			formatWait(wait)), nil
+	}
+	if err != ErrClaimed {
+		return "", err
+	}
+	money, err := store.Balance(userID)
+	if err != nil {
+		return "", err
+	}
+	if money > 0 || config.Bot().ReliefMoney <= 0 {
+		return trf(lang, "You have claimed today's money. Next claim "+
+			"in %s.", formatWait(wait)), nil
+	}
+	// Chips at tables count, as they go back to the wallet.
+	escrowed, err := store.Escrowed(userID)
+	if err != nil {
+		return "", err
+	}
+	if escrowed > 0 {
+		return trn(lang, escrowed, "You still have %d chip at tables, "+
+			"so no relief. Next claim in %s.", "You still have %d chips at "+
+			"tables, so no relief. Next claim in %s.", escrowed,
+			formatWait(wait)), nil
+	}
+	// Bankruptcy relief for empty wallets.
+	reliefWait, err := store.ClaimRelief(userID, config.Bot().ReliefCooldown)
+	if err == ErrClaimed {
+		return trf(lang, "You are broke, but relief is not ready. "+
+			"Next relief in %s.", formatWait(reliefWait)), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	totalMoney, err := store.Credit(userID, config.Bot().ReliefMoney,
+		LedgerEntry{Reason: LedgerRelief})
+	if err != nil {
+		return "", err
+	}
+	return trf(lang, "You are broke! Here is $%d relief and you "+
+		"have $%d now.", config.Bot().ReliefMoney, totalMoney), nil
+}
+
+func handleWallet(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	money, err := store.Balance(user.ID)
+	if err != nil {
+		return err
+	}
+	body := &SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             trf(lang, "You have $%d.", money),
+		ReplyToMessageID: id,
+	}
+	_, err = e.SendMessage(body)
+	return err
+}
+
+func handleExport(e *Bot, id int, chat *Chat, user *User, args string) error {
+	lang := getLanguage(chat.ID)
+	n := 10
+	if args != "" {
+		val, err := strconv.Atoi(args)
+		if err != nil || val <= 0 {
+			_, err := e.SendMessage(&SendMessageRequest{
+				ChatID:           chat.ID,
+				Text:             tr(lang, "Usage: /export <number of hands>"),
+				ReplyToMessageID: id,
+			})
+			return err
+		}
+		n = val
+	}
+	if n > 100 {
+		n = 100
+	}
+	privateChatID, err := store.PrivateChat(user.ID)
+	if err != nil {
+		_, err := e.SendMessage(&SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             tr(lang, err.Error()),
+			ReplyToMessageID: id,
+		})
+		return err
+	}
+	handIDs, err := store.UserHands(user.ID, n)
+	if err != nil {
+		return err
+	}
+	if len(handIDs) == 0 {
+		_, err := e.SendMessage(&SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             tr(lang, "You have not played any hand yet."),
+			ReplyToMessageID: id,
+		})
+		return err
+	}
+	// Oldest hand first, as poker tools expect.
+	text := ""
+	for i := len(handIDs) - 1; i >= 0; i-- {
+		record, err := loadHand(handIDs[i])
+		if err != nil {
+			log.Println("Error:", err, "< handleExport")
+			continue
+		}
+		text += record.PokerStars(user.ID) + "\n\n"
+	}
+	err = sendDocument(privateChatID,
+		fmt.Sprintf("texas-%d-hands.txt", len(handIDs)), []byte(text),
+		trn(lang, int64(len(handIDs)), "Your last %d hand.",
+			"Your last %d hands.", len(handIDs)))
+	if err != nil {
+		return err
+	}
+	if chat.ID != privateChatID {
+		_, err = e.SendMessage(&SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             tr(lang, "Hand history is sent to your private chat."),
+			ReplyToMessageID: id,
+		})
+	}
+	return err
+}
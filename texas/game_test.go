@@ -0,0 +1,34 @@
+package texas
+
+import (
+	"testing"
+	"time"
+
+	"github.com/magicae/texas-holdem-bot/telegramtest"
+)
+
+func TestNoReliefWhileSeated(t *testing.T) {
+	table := newE2ETable(t, "Ivan")
+	user := table.users["Ivan"]
+	private := &telegramtest.Chat{ID: int64(user.ID), Type: "private"}
+	other := &telegramtest.Chat{ID: -int64(newID()), Type: "group",
+		Title: t.Name()}
+	// Buying in at two tables empties the wallet.
+	table.say("Ivan", "/new")
+	table.expect(table.chat.ID, "started a new game!")
+	server.SendMessage(other, user, "/new")
+	table.expect(other.ID, "started a new game!")
+	today, yesterday, _ := getBonusDays(time.Now())
+	_, err := store.ClaimDaily(user.ID, today, yesterday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.SendMessage(private, user, "/getmoney")
+	table.expect(private.ID, "You still have 10000 chips at tables, so no "+
+		"relief.")
+	table.say("Ivan", "/leave")
+	table.expect(table.chat.ID, "Game ends!")
+	server.SendMessage(other, user, "/leave")
+	table.expect(other.ID, "Game ends!")
+	table.checkWallets()
+}
@@ -0,0 +1,171 @@
+package texas
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+var ErrGiveLimit = errors.New("You have reached today's transfer limit.")
+
+type pendingGift struct {
+	To       int
+	ToName   string
+	Amount   int64
+	Deadline time.Time
+}
+
+// Transfers waiting for /confirm, by sender.
+var (
+	pendingGifts      = map[int]*pendingGift{}
+	pendingGiftsMutex sync.Mutex
+)
+
+func handleGive(e *Bot, id int, chat *Chat, user *User, target *User,
+	args string) error {
+	lang := getLanguage(chat.ID)
+	reply := func(text string, markup interface{}) error {
+		body := &SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             text,
+			ReplyToMessageID: id,
+		}
+		if markup != nil {
+			body.ReplyMarkup = markup
+		}
+		_, err := e.SendMessage(body)
+		return err
+	}
+	usage := tr(lang, "Usage: /give @user <amount>, or reply /give <amount> "+
+		"to a message.")
+	fields := strings.Fields(args)
+	toID, toName := 0, ""
+	if len(fields) == 2 && strings.HasPrefix(fields[0], "@") {
+		var err error
+		toID, err = store.UserByName(fields[0][1:])
+		if err != nil {
+			return reply(tr(lang, err.Error()), nil)
+		}
+		toName = fields[0]
+		fields = fields[1:]
+	} else if len(fields) == 1 && target != nil {
+		toID, toName = target.ID, getUserDisplayName(target)
+	} else {
+		return reply(usage, nil)
+	}
+	amount, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || amount <= 0 {
+		return reply(usage, nil)
+	}
+	if toID == user.ID || toID == config.Bot().ID {
+		return reply(trf(lang, "You can't give money to %s.", toName), nil)
+	}
+	if _, err := store.PrivateChat(toID); err != nil {
+		return reply(trf(lang, "%s needs /start in the private chat at "+
+			"first.", toName), nil)
+	}
+	registered, err := store.Registered(user.ID)
+	if err != nil {
+		return err
+	}
+	minAge := config.Bot().GiveMinAccountAge
+	if registered.IsZero() {
+		// Users registered before the times were kept are old enough, but
+		// users who never registered would pass as the oldest accounts.
+		_, err := store.PrivateChat(user.ID)
+		if err == ErrNotRegistered {
+			return reply(tr(lang, ErrNotRegistered.Error()), nil)
+		}
+		if err != nil {
+			return err
+		}
+	} else if age := time.Since(registered); age < minAge {
+		return reply(trf(lang, "Your account is too new to give money. "+
+			"Try again in %s.", formatWait(minAge-age)), nil)
+	}
+	pendingGiftsMutex.Lock()
+	pendingGifts[user.ID] = &pendingGift{
+		To:       toID,
+		ToName:   toName,
+		Amount:   amount,
+		Deadline: time.Now().Add(config.Bot().GiveTimeout),
+	}
+	pendingGiftsMutex.Unlock()
+	return reply(trf(lang, "Give $%d to %s? /confirm or /cancel in %d "+
+		"seconds.", amount, toName, config.Bot().GiveTimeout/time.Second),
+		&ReplyKeyboardMarkup{
+			Keyboard: [][]*KeyboardButton{
+				[]*KeyboardButton{
+					&KeyboardButton{Text: "/confirm"},
+					&KeyboardButton{Text: "/cancel"},
+				},
+			},
+			ResizeKeyboard:  true,
+			OneTimeKeyboard: true,
+			Selective:       true,
+		})
+}
+
+// Take the pending transfer of the user.
+func takePendingGift(userID int) *pendingGift {
+	pendingGiftsMutex.Lock()
+	defer pendingGiftsMutex.Unlock()
+	gift := pendingGifts[userID]
+	delete(pendingGifts, userID)
+	if gift == nil || time.Now().After(gift.Deadline) {
+		return nil
+	}
+	return gift
+}
+
+func handleConfirmGive(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	gift := takePendingGift(user.ID)
+	if gift == nil {
+		return nil
+	}
+	today, _, _ := getBonusDays(time.Now())
+	balance, err := store.Transfer(user.ID, gift.To, gift.Amount,
+		config.Bot().GiveDailyLimit, today)
+	text := ""
+	if err == ErrNoMoney || err == ErrGiveLimit {
+		text = tr(lang, err.Error())
+	} else if err != nil {
+		return err
+	} else {
+		text = trf(lang, "You gave $%d to %s and you have $%d now.",
+			gift.Amount, gift.ToName, balance)
+		chatID, err := store.PrivateChat(gift.To)
+		if err == nil && chatID != 0 {
+			e.SendMessage(&SendMessageRequest{
+				ChatID: chatID,
+				Text: trf(getLanguage(chatID), "%s gave you $%d!",
+					getUserDisplayName(user), gift.Amount),
+			})
+		}
+	}
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	})
+	return err
+}
+
+func handleCancelGive(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	if takePendingGift(user.ID) == nil {
+		return nil
+	}
+	_, err := e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             tr(lang, "Transfer cancelled."),
+		ReplyToMessageID: id,
+	})
+	return err
+}
@@ -0,0 +1,67 @@
+package texas
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/magicae/texas-holdem-bot/telegramtest"
+)
+
+func TestGiveFromUnregistered(t *testing.T) {
+	table := newE2ETable(t, "Ivan")
+	stranger := &telegramtest.User{ID: newID(), FirstName: "Judy",
+		Username: "judy"}
+	_, err := store.Credit(stranger.ID, e2eMoney,
+		LedgerEntry{Reason: LedgerGetMoney})
+	if err != nil {
+		t.Fatal(err)
+	}
+	credited += e2eMoney
+	server.SendMessage(table.chat, stranger, "/give @ivan 500")
+	table.expect(table.chat.ID,
+		"You need /start in the private chat at first!")
+	server.SendMessage(table.chat, stranger, "/confirm")
+	// Nothing is pending, so /confirm is ignored before the wallet shows.
+	server.SendMessage(table.chat, stranger, "/wallet")
+	table.expect(table.chat.ID, fmt.Sprintf("You have $%d.", e2eMoney))
+	table.checkWallets()
+}
+
+// Register the user as before registration times were kept, with a private
+// chat but no time.
+func registerLegacy(t *testing.T, userID int) {
+	switch s := store.(type) {
+	case *MemoryStore:
+		s.mutex.Lock()
+		s.chats[userID] = int64(userID)
+		s.mutex.Unlock()
+	case *RedisStore:
+		err := s.Client.Set(chatKey(userID), userID, 0).Err()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGiveFromLegacyAccount(t *testing.T) {
+	table := newE2ETable(t, "Ivan")
+	legacy := &telegramtest.User{ID: newID(), FirstName: "Kate",
+		Username: "kate"}
+	table.users["Kate"] = legacy
+	registerLegacy(t, legacy.ID)
+	_, err := store.Credit(legacy.ID, e2eMoney,
+		LedgerEntry{Reason: LedgerGetMoney})
+	if err != nil {
+		t.Fatal(err)
+	}
+	credited += e2eMoney
+	// Starting again does not make the account new.
+	private := &telegramtest.Chat{ID: int64(legacy.ID), Type: "private"}
+	server.SendMessage(private, legacy, "/start")
+	table.expect(private.ID, "You are registered in this bot!")
+	table.say("Kate", "/give @ivan 500")
+	table.expect(table.chat.ID, "Give $500 to")
+	table.say("Kate", "/confirm")
+	table.expect(table.chat.ID, "You gave $500 to")
+	table.checkWallets()
+}
@@ -0,0 +1,319 @@
+package texas
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Actions in hand history
+const (
+	ActionSmallBlind = "sb"
+	ActionBigBlind   = "bb"
+	ActionCheck      = "check"
+	ActionCall       = "call"
+	ActionBet        = "bet"
+	ActionRaise      = "raise"
+	ActionFold       = "fold"
+)
+
+type (
+	// HandRecord is the history of a finished hand.
+	HandRecord struct {
+		ID         int64
+		ChatID     int64
+		Time       time.Time
+		Dealer     int
+		SmallBlind int64
+		BigBlind   int64
+		Seats      []*HandSeat
+		Actions    []*HandAction
+		Board      []*PokerCard
+		Pot        int64
+	}
+
+	HandSeat struct {
+		Seat        int
+		UserID      int
+		DisplayName string
+		Chip        int64
+		Cards       [2]*PokerCard
+		// Hand is the rank shown at showdown, -1 if the cards were not shown.
+		Hand int
+		Fold bool
+		Earn int64
+	}
+
+	HandAction struct {
+		Stage  int
+		Seat   int
+		Action string
+		// Amount is the chips put in by this action.
+		Amount int64
+		// To is the total bet of the seat in this stage after this action.
+		To    int64
+		AllIn bool
+	}
+)
+
+// Describe the action, e.g. "raises 200 to 300". max is the highest bet in
+// the stage before the action.
+func (a *HandAction) Text(lang string, max int64) string {
+	text := ""
+	switch a.Action {
+	case ActionSmallBlind:
+		text = trf(lang, "posts small blind %d", a.Amount)
+	case ActionBigBlind:
+		text = trf(lang, "posts big blind %d", a.Amount)
+	case ActionCheck:
+		text = tr(lang, "checks")
+	case ActionFold:
+		text = tr(lang, "folds")
+	case ActionCall:
+		text = trf(lang, "calls %d", a.Amount)
+	case ActionBet:
+		text = trf(lang, "bets %d", a.Amount)
+	case ActionRaise:
+		text = trf(lang, "raises %d to %d", a.To-max, a.To)
+	}
+	if a.AllIn {
+		text += tr(lang, " and is all-in")
+	}
+	return text
+}
+
+// Start recording a new hand. Chips are counted before the blinds.
+func (t *Texas) StartHistory() {
+	id, err := store.NextHandID()
+	if err != nil {
+		log.Println("Error: ", err, "< StartHistory")
+		return
+	}
+	record := &HandRecord{
+		ID:         id,
+		ChatID:     t.ChatID,
+		Time:       time.Now().UTC(),
+		Dealer:     t.Round.Dealer,
+		SmallBlind: t.SmallBlind,
+		BigBlind:   t.BigBlind,
+		Seats:      make([]*HandSeat, 0),
+		Actions:    make([]*HandAction, 0),
+	}
+	for i := 0; i < 10; i++ {
+		if t.Players[i] != nil && t.Round.UserState[i] == InGame {
+			record.Seats = append(record.Seats, &HandSeat{
+				Seat:        i,
+				UserID:      t.Players[i].UserID,
+				DisplayName: t.Players[i].DisplayName,
+				Chip:        t.Players[i].Chip,
+				Hand:        -1,
+			})
+		}
+	}
+	t.Round.History = record
+}
+
+// Find the record of a seat.
+func (r *HandRecord) Seat(index int) *HandSeat {
+	for _, seat := range r.Seats {
+		if seat.Seat == index {
+			return seat
+		}
+	}
+	return nil
+}
+
+// Record an action of the player at index.
+func (t *Texas) RecordAction(index int, action string, amount int64) {
+	if t.Round.History == nil {
+		return
+	}
+	t.Round.History.Actions = append(t.Round.History.Actions, &HandAction{
+		Stage:  t.Round.Stage,
+		Seat:   index,
+		Action: action,
+		Amount: amount,
+		To:     t.Round.StageBets[index],
+		AllIn:  t.Players[index].Chip <= 0,
+	})
+}
+
+// Finish the record with the result of the round and save it.
+func (t *Texas) SaveHistory() {
+	record := t.Round.History
+	if record == nil {
+		return
+	}
+	t.Round.History = nil
+	t.LastHandID = record.ID
+	record.Pot = t.Round.Pot
+	record.Board = make([]*PokerCard, 0, 5)
+	for i := 0; i < 5; i++ {
+		if t.Round.CommunityCards[i] != nil {
+			record.Board = append(record.Board, t.Round.CommunityCards[i])
+		}
+	}
+	userIDs := make([]int, 0, len(record.Seats))
+	for _, seat := range record.Seats {
+		seat.Cards = t.Round.PlayerCards[seat.Seat]
+		seat.Fold = t.Round.UserState[seat.Seat] != InGame
+		seat.Earn = t.Round.Earn[seat.Seat]
+		if !seat.Fold && t.Round.TopCards[seat.Seat] != nil &&
+			len(record.Board) == 5 && t.CountUserInGame() > 1 {
+			seat.Hand = t.Round.TopCards[seat.Seat].GetRank()
+		}
+		userIDs = append(userIDs, seat.UserID)
+	}
+	data, err := json.Marshal(record)
+	if err == nil {
+		err = store.SaveHand(record.ID, record.ChatID, userIDs, data)
+	}
+	if err != nil {
+		log.Println("Error: ", err, "< SaveHistory")
+	}
+	observeHand()
+	stats := getHandStats(record)
+	saveStats(record, stats)
+	t.SaveRanks(record, stats)
+}
+
+// Load a hand from history.
+func loadHand(id int64) (*HandRecord, error) {
+	data, err := store.LoadHand(id)
+	if err != nil {
+		return nil, err
+	}
+	record := &HandRecord{}
+	err = json.Unmarshal(data, record)
+	return record, err
+}
+
+var pokerStarsStreets = map[int]string{
+	Flop:  "FLOP",
+	Turn:  "TURN",
+	River: "RIVER",
+}
+
+func getPokerStarsCards(cards []*PokerCard) string {
+	texts := make([]string, 0, len(cards))
+	for _, card := range cards {
+		texts = append(texts, getPokerNotation(card))
+	}
+	return "[" + strings.Join(texts, " ") + "]"
+}
+
+// Format a hand in PokerStars hand history format, as seen by hero.
+func (r *HandRecord) PokerStars(hero int) string {
+	text := fmt.Sprintf("PokerStars Hand #%d:  Hold'em No Limit (%d/%d) - %s\n",
+		r.ID, r.SmallBlind, r.BigBlind, r.Time.Format("2006/01/02 15:04:05 UTC"))
+	text += fmt.Sprintf("Table 'Texas %d' 10-max Seat #%d is the button\n",
+		r.ChatID, r.Dealer+1)
+	for _, seat := range r.Seats {
+		text += fmt.Sprintf("Seat %d: %s (%d in chips)\n", seat.Seat+1,
+			seat.DisplayName, seat.Chip)
+	}
+	holeCards := false
+	street := Preflop
+	var max int64 = 0
+	for _, action := range r.Actions {
+		seat := r.Seat(action.Seat)
+		if seat == nil {
+			continue
+		}
+		if !holeCards && action.Stage >= Preflop {
+			holeCards = true
+			text += r.pokerStarsHoleCards(hero)
+		}
+		for street < action.Stage {
+			street++
+			max = 0
+			text += r.pokerStarsStreet(street)
+		}
+		// PokerStars format is always in English.
+		text += seat.DisplayName + ": " + action.Text("en", max) + "\n"
+		if action.To > max {
+			max = action.To
+		}
+	}
+	if !holeCards {
+		text += r.pokerStarsHoleCards(hero)
+	}
+	for street < River && len(r.Board) >= street-Preflop+3 {
+		street++
+		text += r.pokerStarsStreet(street)
+	}
+	showdown := false
+	for _, seat := range r.Seats {
+		if seat.Hand >= 0 {
+			if !showdown {
+				text += "*** SHOW DOWN ***\n"
+				showdown = true
+			}
+			text += fmt.Sprintf("%s: shows %s (%s)\n", seat.DisplayName,
+				getPokerStarsCards(seat.Cards[:]),
+				strings.ToLower(PokerHands[seat.Hand]))
+		}
+	}
+	for _, seat := range r.Seats {
+		if seat.Earn > 0 {
+			text += fmt.Sprintf("%s collected %d from pot\n", seat.DisplayName,
+				seat.Earn)
+		}
+	}
+	text += "*** SUMMARY ***\n"
+	text += fmt.Sprintf("Total pot %d | Rake 0\n", r.Pot)
+	if len(r.Board) > 0 {
+		text += "Board " + getPokerStarsCards(r.Board) + "\n"
+	}
+	for _, seat := range r.Seats {
+		text += fmt.Sprintf("Seat %d: %s", seat.Seat+1, seat.DisplayName)
+		if seat.Seat == r.Dealer {
+			text += " (button)"
+		}
+		if seat.Fold {
+			text += " folded"
+		} else if seat.Hand >= 0 {
+			text += " showed " + getPokerStarsCards(seat.Cards[:])
+			if seat.Earn > 0 {
+				text += fmt.Sprintf(" and won (%d)", seat.Earn)
+			} else {
+				text += " and lost"
+			}
+		} else if seat.Earn > 0 {
+			text += fmt.Sprintf(" collected (%d)", seat.Earn)
+		}
+		text += "\n"
+	}
+	return text
+}
+
+// Hole cards dealt to hero.
+func (r *HandRecord) pokerStarsHoleCards(hero int) string {
+	text := "*** HOLE CARDS ***\n"
+	for _, seat := range r.Seats {
+		if seat.UserID == hero && seat.Cards[0] != nil {
+			text += fmt.Sprintf("Dealt to %s %s\n", seat.DisplayName,
+				getPokerStarsCards(seat.Cards[:]))
+		}
+	}
+	return text
+}
+
+// Header and cards of a street.
+func (r *HandRecord) pokerStarsStreet(stage int) string {
+	name, ok := pokerStarsStreets[stage]
+	if !ok {
+		return ""
+	}
+	n := stage - Preflop + 2
+	if len(r.Board) < n {
+		return ""
+	}
+	if stage == Flop {
+		return "*** FLOP *** " + getPokerStarsCards(r.Board[:3]) + "\n"
+	}
+	return "*** " + name + " *** " + getPokerStarsCards(r.Board[:n-1]) + " " +
+		getPokerStarsCards(r.Board[n-1:n]) + "\n"
+}
@@ -0,0 +1,105 @@
+package texas
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// Catalog translates messages to a language. Messages are keyed by their
+// English text, which is shown when a message is not translated. A language
+// is added by a catalog file registering itself in catalogs.
+type Catalog struct {
+	// Name of the language in itself.
+	Name string
+	// Plural picks the form for n among the forms of a plural message.
+	Plural   func(n int64) int
+	Messages map[string]string
+	// Plurals are keyed by the English singular.
+	Plurals map[string][]string
+}
+
+// Catalogs by language code.
+var catalogs = map[string]*Catalog{}
+
+// Language codes in order.
+func getLanguages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Language of a chat. The private chat of a user has the ID of the user.
+func getLanguage(chatID int64) string {
+	lang, err := store.Language(chatID)
+	if err == nil && catalogs[lang] != nil {
+		return lang
+	}
+	if catalogs[config.Bot().Language] != nil {
+		return config.Bot().Language
+	}
+	return "en"
+}
+
+// Translate a message.
+func tr(lang string, text string) string {
+	if catalog := catalogs[lang]; catalog != nil {
+		if translated, ok := catalog.Messages[text]; ok {
+			return translated
+		}
+	}
+	return text
+}
+
+// Translate a message and format it.
+func trf(lang string, format string, args ...interface{}) string {
+	return fmt.Sprintf(tr(lang, format), args...)
+}
+
+// Translate a message counting n and format it. singular and plural are the
+// English forms.
+func trn(lang string, n int64, singular string, plural string,
+	args ...interface{}) string {
+	format := plural
+	if n == 1 {
+		format = singular
+	}
+	if catalog := catalogs[lang]; catalog != nil {
+		if forms, ok := catalog.Plurals[singular]; ok {
+			format = forms[catalog.Plural(n)]
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func handleLanguage(e *Bot, id int, chat *Chat, user *User, args string) error {
+	lang := getLanguage(chat.ID)
+	text := ""
+	args = strings.ToLower(args)
+	if catalogs[args] != nil {
+		err := store.SetLanguage(chat.ID, args)
+		if err != nil {
+			return err
+		}
+		lang = args
+		text = tr(lang, "Language is set to English.")
+	} else {
+		text = trf(lang, "Language: %s\nUse /lang <code> to change it:",
+			catalogs[lang].Name)
+		for _, code := range getLanguages() {
+			text += "\n" + code + " - " + catalogs[code].Name
+		}
+	}
+	_, err := e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	})
+	return err
+}
@@ -0,0 +1,17 @@
+package texas
+
+// English is the language messages are written in, so only its plural rule
+// is needed.
+func init() {
+	catalogs["en"] = &Catalog{
+		Name: "English",
+		Plural: func(n int64) int {
+			if n == 1 {
+				return 0
+			}
+			return 1
+		},
+		Messages: map[string]string{},
+		Plurals:  map[string][]string{},
+	}
+}
@@ -0,0 +1,246 @@
+package texas
+
+// Simplified Chinese.
+func init() {
+	catalogs["zh"] = &Catalog{
+		Name: "简体中文",
+		Plural: func(n int64) int {
+			return 0
+		},
+		Messages: map[string]string{
+			// Stages
+			"Init":            "准备",
+			"Compulsory Bets": "盲注",
+			"Preflop":         "翻牌前",
+			"Flop":            "翻牌",
+			"Turn":            "转牌",
+			"River":           "河牌",
+			"Showdown":        "摊牌",
+			"End":             "结束",
+			// Hands
+			"HIGH CARD":       "高牌",
+			"ONE PAIR":        "一对",
+			"TWO PAIRS":       "两对",
+			"THREE OF A KIND": "三条",
+			"STRAIGHT":        "顺子",
+			"FLUSH":           "同花",
+			"FULL HOUSE":      "葫芦",
+			"FOUR OF A KIND":  "四条",
+			"STRAIGHT FLUSH":  "同花顺",
+			"ROYAL FLUSH":     "皇家同花顺",
+			"FIVE OF A KIND":  "五条",
+			// Table
+			"- %s - Pot: %d":             "- %s - 底池：%d",
+			"Community cards:":           "公共牌：",
+			"= SHOWDOWN =":               "= 摊牌 =",
+			"FOLD":                       "弃牌",
+			"WIN +%d":                    "赢 +%d",
+			"LOSE":                       "输",
+			"*ALL IN*":                   "*全下*",
+			"(Dealer)":                   "（庄家）",
+			"Waiting %s (@%s)...":        "等待 %s (@%s)……",
+			"%s (@%s) gets out of game!": "%s (@%s) 出局了！",
+			"[%s] You got %s!":           "[%s] 你的牌型是%s！",
+			"New round starts! Dealing for you. Good luck!": "新一局开始！正在为你发牌，祝你好运！",
+			"Table restored! Sorry for the interruption.":   "牌桌已恢复！抱歉打扰了。",
+			"Next hand":                       "下一局",
+			"Leave":                           "离开",
+			"Check":                           "过牌",
+			"Call %d":                         "跟注 %d",
+			"Fold":                            "弃牌",
+			"%s to %d":                        "%s 加到 %d",
+			"All-in to %d":                    "全下到 %d",
+			"Min":                             "最小",
+			"½ pot":                           "½ 底池",
+			"¾ pot":                           "¾ 底池",
+			"Pot":                             "底池",
+			"2× pot":                          "2× 底池",
+			"This table message is outdated.": "这条牌桌消息已过期。",
+			"It is not your turn.":            "还没轮到你。",
+			// Game
+			"Texas Hold'em has already started.\n/join":                   "德州扑克已经开始了。\n/join",
+			"Failed to start a new game.":                                 "开始新游戏失败。",
+			"Failed to join game.":                                        "加入游戏失败。",
+			"You need to /new game first!":                                "请先 /new 开始游戏！",
+			"Game is not ready.":                                          "游戏还没准备好。",
+			"Texas Hold'em Players (%d / 10)":                             "德州扑克玩家（%d / 10）",
+			"Bye! You took $%d back!":                                     "再见！你带走了 $%d！",
+			"Game ends!":                                                  "游戏结束！",
+			"You need to /join game at first.":                            "请先 /join 加入游戏。",
+			"You are registered in this bot! Lets start a game in group.": "你已经注册了！去群里开一局吧。",
+			"You have been in this game.":                                 "你已经在游戏中了。",
+			"You are too poor to join game.":                              "你的钱不够加入游戏。",
+			"There are no seats for you.":                                 "没有空位了。",
+			"You are currently not in this game.":                         "你不在这局游戏中。",
+			"This round is still taking.":                                 "这一局还在进行中。",
+			"Not enough players to start a new round.":                    "玩家不足，无法开始新一局。",
+			"Round is not ready.":                                         "这一局还没准备好。",
+			"You can only /call, /raise or /fold.":                        "你只能 /call、/raise 或 /fold。",
+			"You can only /check, /raise or /fold.":                       "你只能 /check、/raise 或 /fold。",
+			"Cannot /raise less than the big blind.":                      "加注不能少于大盲注。",
+			"No enough chips for raising. /allin?":                        "筹码不够加注。/allin？",
+			// Wallet
+			"Wow! You got $%d and you have $%d now!":                             "哇！你获得了 $%d，现在有 $%d！",
+			"%d days in a row!":                                                  "已连续 %d 天！",
+			"Next claim in %s.":                                                  "下次领取还需 %s。",
+			"You have claimed today's money. Next claim in %s.":                  "你今天已经领过了。下次领取还需 %s。",
+			"You are broke, but relief is not ready. Next relief in %s.":         "你破产了，但救济金还没准备好。下次救济还需 %s。",
+			"You are broke! Here is $%d relief and you have $%d now.":            "你破产了！这是 $%d 救济金，你现在有 $%d。",
+			"You have $%d.":                                                      "你有 $%d。",
+			"You need /start in the private chat at first!":                      "请先在私聊中 /start！",
+			"You don't have enough money.":                                       "你的钱不够。",
+			"User is not found.":                                                 "找不到该用户。",
+			"Already claimed.":                                                   "已经领过了。",
+			"Your last wallet changes (UTC):":                                    "你最近的钱包变动（UTC）：",
+			"Your wallet has not changed yet.":                                   "你的钱包还没有变动。",
+			"No ledger entries, balance $%d.":                                    "没有账目，余额 $%d。",
+			"%s, expected $%d":                                                   "%s，应为 $%d",
+			"Balance is $%d, but ledger ends with $%d":                           "余额为 $%d，但账目结余为 $%d",
+			"Ledger matches the balance.":                                        "账目与余额一致。",
+			"Mismatches:":                                                        "不一致：",
+			"Usage: /give @user <amount>, or reply /give <amount> to a message.": "用法：/give @用户 <金额>，或回复消息 /give <金额>。",
+			"You can't give money to %s.":                                        "你不能给 %s 转钱。",
+			"%s needs /start in the private chat at first.":                      "%s 需要先在私聊中 /start。",
+			"Your account is too new to give money. Try again in %s.":            "你的账号太新，还不能转钱。请 %s 后再试。",
+			"Give $%d to %s? /confirm or /cancel in %d seconds.":                 "给 %[2]s 转 $%[1]d？请在 %[3]d 秒内 /confirm 或 /cancel。",
+			"You have reached today's transfer limit.":                           "你已达到今天的转账上限。",
+			"You gave $%d to %s and you have $%d now.":                           "你给 %[2]s 转了 $%[1]d，现在有 $%[3]d。",
+			"%s gave you $%d!":                                                   "%s 给你转了 $%d！",
+			"Transfer cancelled.":                                                "转账已取消。",
+			// History
+			"Usage: /export <number of hands>":           "用法：/export <手数>",
+			"You have not played any hand yet.":          "你还没有玩过任何一手牌。",
+			"Hand history is sent to your private chat.": "手牌记录已发送到你的私聊。",
+			"Hand is not found.":                         "找不到这手牌。",
+			"Table is not found.":                        "找不到牌桌。",
+			"Usage: /replay <hand id>":                   "用法：/replay <手牌编号>",
+			"You can only replay hands of this group or your own hands in the private chat.": "你只能回放本群的手牌，或在私聊中回放自己的手牌。",
+			"Replaying hand #%d...":  "正在回放第 #%d 手……",
+			"= REPLAY #%d =":         "= 回放 #%d =",
+			"= END OF #%d = Pot: %d": "= #%d 结束 = 底池：%d",
+			"posts small blind %d":   "下小盲注 %d",
+			"posts big blind %d":     "下大盲注 %d",
+			"checks":                 "过牌",
+			"folds":                  "弃牌",
+			"calls %d":               "跟注 %d",
+			"bets %d":                "下注 %d",
+			"raises %d to %d":        "加注 %d 到 %d",
+			" and is all-in":         "，全下",
+			// Stats
+			"%s has not played any hand yet.": "%s 还没有玩过任何一手牌。",
+			"Stats of %s":                     "%s 的统计",
+			"(this group / all groups)":       "（本群 / 所有群）",
+			"Hands":                           "手数",
+			"Net":                             "净赢",
+			"Nobody yet.":                     "暂时没有人。",
+			"%d. %s - %+.0f / 100 hands":      "%d. %s - %+.0f / 100 手",
+			"Richest:":                        "最富有：",
+			"Net winnings:":                   "净赢：",
+			"Win rate (%d+ hands):":           "胜率（%d 手以上）：",
+			"Top players (global)":            "排行榜（全局）",
+			"Top players (this group, /top global for all)": "排行榜（本群，/top global 查看全局）",
+			"Season %d is over! Final standings:":           "第 %d 赛季结束！最终排名：",
+			// Commands
+			"Unknown command /%s. Try one of these:": "未知命令 /%s。试试这些：",
+			"/%s only works in groups.":              "/%s 只能在群里使用。",
+			"/%s only works in the private chat.":    "/%s 只能在私聊中使用。",
+			"Usage:":                                 "用法：",
+			"Table is busy.":                         "牌桌正忙。",
+			"Table timed out.":                       "牌桌超时。",
+			"start a new game":                       "开始新游戏",
+			"join the game":                          "加入游戏",
+			"leave the game with your chips":         "带着筹码离开游戏",
+			"list players":                           "列出玩家",
+			"deal a new hand":                        "发新的一手牌",
+			"check":                                  "过牌",
+			"call":                                   "跟注",
+			"raise by the amount, or show the raise sizes": "加注一定数额，或显示加注选项",
+			"raise to the total bet of this stage":         "加注到本轮总下注额",
+			"raise the pot":                                "加注一个底池",
+			"go all-in":                                    "全下",
+			"fold":                                         "弃牌",
+			"get a token to play on the web":               "获取网页版游戏的令牌",
+			"register to play":                             "注册",
+			"claim the daily money":                        "领取每日奖励",
+			"show your money":                              "查看你的钱",
+			"give money to a user, or to the one you reply to": "给用户转钱，或给你回复的人转钱",
+			"confirm your transfer":                            "确认转账",
+			"cancel your transfer":                             "取消转账",
+			"show your wallet history":                         "查看钱包记录",
+			"show player statistics":                           "查看玩家统计",
+			"show the leaderboards":                            "查看排行榜",
+			"send your hand history in PokerStars format":      "以 PokerStars 格式发送手牌记录",
+			"replay a hand":                                    "回放一手牌",
+			"check a wallet (admins)":                          "检查钱包（管理员）",
+			"show or set the language of this chat":            "查看或设置本聊天的语言",
+			"show this help":                                   "显示帮助",
+			"show or set how cards are shown in this chat":     "查看或设置本聊天中牌的显示方式",
+			"Cards are shown as %s.":                           "牌将显示为%s。",
+			"Cards: %s\nUse /cards <style> to change it:":      "牌的显示方式：%s\n使用 /cards <方式> 切换：",
+			"pictures of the table and cards":                  "牌桌和牌的图片",
+			"card stickers":                                    "扑克牌贴纸",
+			"text with suit emoji":                             "带花色表情的文字",
+			"plain text like As Kd":                            "纯文本，如 As Kd",
+			"text with four suit colors":                       "四色花色文字",
+			"words for screen readers, like Ace of spades":     "适合读屏的文字，如黑桃A",
+			"unknown card":                                     "未知的牌",
+			"%s of %s":                                         "%[2]s%[1]s",
+			"Two":                                              "2",
+			"Three":                                            "3",
+			"Four":                                             "4",
+			"Five":                                             "5",
+			"Six":                                              "6",
+			"Seven":                                            "7",
+			"Eight":                                            "8",
+			"Nine":                                             "9",
+			"Ten":                                              "10",
+			"Jack":                                             "J",
+			"Queen":                                            "Q",
+			"King":                                             "K",
+			"Ace":                                              "A",
+			"diamonds":                                         "方块",
+			"hearts":                                           "红心",
+			"clubs":                                            "梅花",
+			"spades":                                           "黑桃",
+			"Table is paused. Table owners can /resume it.":                        "牌桌已暂停。桌主可以 /resume 恢复。",
+			"Only the table owner and group admins can /%s.":                       "只有桌主和群管理员可以 /%s。",
+			"The user is not in this game.":                                        "该用户不在这局游戏中。",
+			"%s is kicked and took $%d back.":                                      "%s 被踢出，带走了 $%d。",
+			"%s paused the table. Nobody can act until /resume.":                   "%s 暂停了牌桌。/resume 之前谁都不能行动。",
+			"%s resumed the table.":                                                "%s 恢复了牌桌。",
+			"The bot is going down for maintenance. Everyone took the chips back:": "机器人即将停机维护，所有人带走了筹码：",
+			"%s ended the game. Everyone took the chips back:":                     "%s 结束了游戏。所有人带走了筹码：",
+			"fold a player and send the chips back (table owners)":                 "让玩家弃牌并退还筹码（桌主）",
+			"pause the table (table owners)":                                       "暂停牌桌（桌主）",
+			"resume the table (table owners)":                                      "恢复牌桌（桌主）",
+			"end the game and send everyone's chips back (table owners)":           "结束游戏并退还所有人的筹码（桌主）",
+			"Playing on the web is not enabled.":                                   "网页版游戏未开启。",
+			"Language is set to English.":                                          "语言已设置为简体中文。",
+			"Language: %s\nUse /lang <code> to change it:":                         "语言：%s\n使用 /lang <代码> 切换：",
+			"ALL IN": "全下",
+			"%s made the table public. Web players can find and join it.":    "%s 公开了牌桌，网页玩家可以找到并加入。",
+			"%s made the table private. Only its players see it on the web.": "%s 隐藏了牌桌，网页上只有桌上的玩家能看到。",
+			"let web players find and join the table (table owners)":         "让网页玩家找到并加入牌桌（桌主）",
+			"hide the table from web players (table owners)":                 "对网页玩家隐藏牌桌（桌主）",
+		},
+		Plurals: map[string][]string{
+			"You still have %d chip at tables, so no relief. Next claim in %s.": {
+				"你在牌桌上还有 %d 筹码，不能领救济金。下次领取还需 %s。",
+			},
+			"%s bought %d chip and started a new game!\n/join us to play Texas Hold'em together!": {
+				"%s 买了 %d 筹码，开始了新游戏！\n/join 一起来玩德州扑克吧！",
+			},
+			"%s (@%s) bought %d chip and joined the game!": {
+				"%s (@%s) 买了 %d 筹码，加入了游戏！",
+			},
+			"-> %d chip.":        {"-> %d 筹码。"},
+			"Your last %d hand.": {"你最近的 %d 手牌。"},
+			"%d entry, opening $%d, balance $%d.": {
+				"%d 条账目，期初 $%d，余额 $%d。",
+			},
+			"Your token to play on the web, valid for %d day. Keep it secret:": {
+				"你的网页版游戏令牌，%d 天内有效，请妥善保管：",
+			},
+		},
+	}
+}
@@ -0,0 +1,185 @@
+package texas
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// Leaderboards
+const (
+	// Wallet and chips at tables.
+	BoardMoney = "money"
+	// Chips won in hands.
+	BoardNet = "net"
+	// Hands played, only used to count win rate.
+	BoardHands = "hands"
+	// Chips won per 100 hands.
+	BoardWinRate = "winrate"
+)
+
+var Leaderboards = []string{BoardMoney, BoardNet, BoardHands, BoardWinRate}
+
+// Players need to play this many hands to be ranked by win rate.
+const minRankedHands = 20
+
+// Update leaderboards of the group and global ones with a finished hand.
+func (t *Texas) SaveRanks(record *HandRecord, stats map[int]map[string]int64) {
+	for _, seat := range record.Seats {
+		userID := seat.UserID
+		money, err := store.Balance(userID)
+		if err != nil {
+			log.Println("Error: ", err, "< SaveRanks")
+			continue
+		}
+		if t.Players[seat.Seat] != nil {
+			money += t.Players[seat.Seat].Chip + t.Round.Earn[seat.Seat]
+		}
+		for _, chatID := range []int64{record.ChatID, 0} {
+			err = store.SetRank(BoardMoney, chatID, userID, float64(money))
+			if err != nil {
+				log.Println("Error: ", err, "< SaveRanks")
+				continue
+			}
+			net, err := store.IncrRank(BoardNet, chatID, userID,
+				float64(stats[seat.Seat][StatNet]))
+			if err != nil {
+				log.Println("Error: ", err, "< SaveRanks")
+				continue
+			}
+			hands, err := store.IncrRank(BoardHands, chatID, userID, 1)
+			if err != nil {
+				log.Println("Error: ", err, "< SaveRanks")
+				continue
+			}
+			if hands >= minRankedHands {
+				err = store.SetRank(BoardWinRate, chatID, userID,
+					net*100/hands)
+				if err != nil {
+					log.Println("Error: ", err, "< SaveRanks")
+				}
+			}
+		}
+	}
+}
+
+// Text of the top n users of a leaderboard.
+func getRanksText(lang string, board string, chatID int64, n int) (string,
+	error) {
+	ranks, err := store.Ranks(board, chatID, n)
+	if err != nil {
+		return "", err
+	}
+	if len(ranks) == 0 {
+		return tr(lang, "Nobody yet.") + "\n", nil
+	}
+	text := ""
+	for i, rank := range ranks {
+		name, err := store.DisplayName(rank.UserID)
+		if err != nil {
+			name = fmt.Sprintf("#%d", rank.UserID)
+		}
+		switch board {
+		case BoardMoney:
+			text += fmt.Sprintf("%d. %s - $%.0f\n", i+1, name, rank.Score)
+		case BoardWinRate:
+			text += trf(lang, "%d. %s - %+.0f / 100 hands", i+1, name,
+				rank.Score) + "\n"
+		default:
+			text += fmt.Sprintf("%d. %s - %+.0f\n", i+1, name, rank.Score)
+		}
+	}
+	return text, nil
+}
+
+// Text of all leaderboards of a group, or global ones if chatID is 0.
+func getLeaderboardsText(lang string, chatID int64) (string, error) {
+	text := ""
+	for _, board := range []string{BoardMoney, BoardNet, BoardWinRate} {
+		switch board {
+		case BoardMoney:
+			text += tr(lang, "Richest:") + "\n"
+		case BoardNet:
+			text += tr(lang, "Net winnings:") + "\n"
+		case BoardWinRate:
+			text += trf(lang, "Win rate (%d+ hands):", minRankedHands) + "\n"
+		}
+		ranks, err := getRanksText(lang, board, chatID, 10)
+		if err != nil {
+			return "", err
+		}
+		text += ranks
+	}
+	return text, nil
+}
+
+func handleTop(e *Bot, id int, chat *Chat, user *User, args string) error {
+	lang := getLanguage(chat.ID)
+	var chatID int64 = 0
+	title := tr(lang, "Top players (global)") + "\n"
+	if (chat.Type == "group" || chat.Type == "supergroup") &&
+		args != "global" {
+		chatID = chat.ID
+		title = tr(lang, "Top players (this group, /top global for all)") +
+			"\n"
+	}
+	text, err := getLeaderboardsText(lang, chatID)
+	if err != nil {
+		return err
+	}
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             title + text,
+		ReplyToMessageID: id,
+	})
+	return err
+}
+
+// End seasons when they are due.
+func runSeasons(e *Bot) {
+	for {
+		season, start, err := store.Season()
+		if err != nil {
+			log.Println("Error:", err, "< runSeasons")
+		} else if season == 0 {
+			// Seasons were just turned on. Standings so far count for the
+			// first season.
+			err = store.StartSeason(0, time.Now())
+		} else if time.Since(start) >=
+			time.Duration(config.Bot().SeasonDays)*24*time.Hour {
+			err = endSeason(e, season)
+		}
+		if err != nil {
+			log.Println("Error:", err, "< runSeasons")
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+// Post final standings to every group and start a new season.
+func endSeason(e *Bot, season int64) error {
+	chatIDs, err := store.RankedChats()
+	if err != nil {
+		return err
+	}
+	for _, chatID := range chatIDs {
+		lang := getLanguage(chatID)
+		text, err := getLeaderboardsText(lang, chatID)
+		if err != nil {
+			return err
+		}
+		_, err = e.SendMessage(&SendMessageRequest{
+			ChatID: chatID,
+			Text: trf(lang, "Season %d is over! Final standings:", season) +
+				"\n" + text,
+		})
+		if err != nil {
+			log.Println("Error:", err, "< endSeason")
+		}
+	}
+	log.Println("Season", season, "ends")
+	return store.StartSeason(season, time.Now())
+}
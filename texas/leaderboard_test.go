@@ -0,0 +1,44 @@
+package texas
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFirstSeasonKeepsStandings(t *testing.T) {
+	s := NewMemoryStore()
+	err := s.SetRank(BoardMoney, 0, 1, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.StartSeason(0, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	season, _, err := s.Season()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if season != 1 {
+		t.Errorf("Season %d starts, want 1", season)
+	}
+	ranks, err := s.Ranks(BoardMoney, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranks) != 1 || ranks[0].UserID != 1 || ranks[0].Score != 500 {
+		t.Errorf("Standings after the first season starts: %v", ranks)
+	}
+	// Later seasons start from empty leaderboards.
+	err = s.StartSeason(season, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranks, err = s.Ranks(BoardMoney, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranks) != 0 {
+		t.Errorf("Standings after the second season starts: %v", ranks)
+	}
+}
@@ -0,0 +1,151 @@
+package texas
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// Reasons of wallet changes
+const (
+	LedgerGetMoney = "getmoney"
+	LedgerRelief   = "relief"
+	LedgerGive     = "give"
+	LedgerReceive  = "receive"
+	LedgerBuyIn    = "buyin"
+	LedgerCashOut  = "cashout"
+	LedgerRefund   = "refund"
+)
+
+// LedgerEntry is a change of a wallet. Time, Amount and Balance are filled
+// by the store.
+type LedgerEntry struct {
+	Time   int64
+	Reason string
+	ChatID int64 `json:",omitempty"`
+	HandID int64 `json:",omitempty"`
+	// Peer is the other user of a transfer.
+	Peer   int `json:",omitempty"`
+	Amount int64
+	// Balance is the money in the wallet after the change.
+	Balance int64
+}
+
+func (e *LedgerEntry) String() string {
+	text := time.Unix(e.Time, 0).UTC().Format("01-02 15:04") +
+		fmt.Sprintf(" %+d %s", e.Amount, e.Reason)
+	if e.HandID != 0 {
+		text += fmt.Sprintf(" #%d", e.HandID)
+	}
+	if e.Peer != 0 {
+		name, err := store.DisplayName(e.Peer)
+		if err != nil {
+			name = strconv.Itoa(e.Peer)
+		}
+		text += " " + name
+	}
+	return text + fmt.Sprintf(" -> $%d", e.Balance)
+}
+
+func isAdmin(userID int) bool {
+	for _, admin := range config.Bot().Admins {
+		if admin == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func handleLedger(e *Bot, id int, chat *Chat, user *User) error {
+	lang := getLanguage(chat.ID)
+	entries, err := store.Ledger(user.ID, 10)
+	if err != nil {
+		return err
+	}
+	text := tr(lang, "Your last wallet changes (UTC):") + "\n"
+	if len(entries) == 0 {
+		text = tr(lang, "Your wallet has not changed yet.")
+	}
+	for _, entry := range entries {
+		text += entry.String() + "\n"
+	}
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	})
+	return err
+}
+
+// Check every ledger entry follows the one before and the last one matches
+// the balance.
+func auditLedger(lang string, userID int) (string, error) {
+	entries, err := store.Ledger(userID, 0)
+	if err != nil {
+		return "", err
+	}
+	balance, err := store.Balance(userID)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return trf(lang, "No ledger entries, balance $%d.", balance), nil
+	}
+	problems := make([]string, 0)
+	// Entries are the latest first.
+	oldest := entries[len(entries)-1]
+	opening := oldest.Balance - oldest.Amount
+	expected := opening
+	for i := len(entries) - 1; i >= 0; i-- {
+		expected += entries[i].Amount
+		if entries[i].Balance != expected {
+			problems = append(problems, trf(lang, "%s, expected $%d",
+				entries[i].String(), expected))
+			expected = entries[i].Balance
+		}
+	}
+	if balance != expected {
+		problems = append(problems, trf(lang,
+			"Balance is $%d, but ledger ends with $%d", balance, expected))
+	}
+	text := trn(lang, int64(len(entries)),
+		"%d entry, opening $%d, balance $%d.",
+		"%d entries, opening $%d, balance $%d.",
+		len(entries), opening, balance) + "\n"
+	if len(problems) == 0 {
+		return text + tr(lang, "Ledger matches the balance."), nil
+	}
+	return text + tr(lang, "Mismatches:") + "\n" +
+		strings.Join(problems, "\n"), nil
+}
+
+func handleAudit(e *Bot, id int, chat *Chat, user *User, args string) error {
+	lang := getLanguage(chat.ID)
+	if !isAdmin(user.ID) {
+		return nil
+	}
+	userID := user.ID
+	var err error
+	if strings.HasPrefix(args, "@") {
+		userID, err = store.UserByName(args[1:])
+	} else if args != "" {
+		userID, err = strconv.Atoi(args)
+	}
+	text := ""
+	if err == nil {
+		text, err = auditLedger(lang, userID)
+	}
+	if err != nil {
+		text = tr(lang, err.Error())
+	}
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID:           chat.ID,
+		Text:             text,
+		ReplyToMessageID: id,
+	})
+	return err
+}
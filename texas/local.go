@@ -0,0 +1,28 @@
+package texas
+
+import (
+	. "github.com/magicae/telegram-bot"
+)
+
+// Functions for running tables in the process without Telegram updates,
+// like cmd/texas-cli does.
+
+// Use the storage for wallets, tables and history.
+func UseStore(s Store) {
+	store = s
+}
+
+// Handle a message as if it came from Telegram, and return once it is done.
+func HandleMessage(e *Bot, message *Message) {
+	criticalTextMessageHandler(e, message)
+}
+
+// Get the game of a chat, nil if there is not one.
+func GetGame(chatID int64) *Texas {
+	return getGame(chatID)
+}
+
+// Get the largest bet of this stage.
+func (t *Texas) MaxBet() int64 {
+	return t.getMaxBet()
+}
@@ -0,0 +1,343 @@
+package texas
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// Buckets of handler latencies in seconds.
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5,
+	10}
+
+// How long long polling may go without a successful poll before /healthz
+// fails.
+const healthPollAge = 2 * time.Minute
+
+// How long the money in wallets is kept, as it is summed from every wallet.
+const walletsCacheAge = time.Minute
+
+// counterVec counts by the value of a label.
+type counterVec struct {
+	mutex  sync.Mutex
+	values map[string]float64
+}
+
+func (c *counterVec) Add(label string, value float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.values[label] += value
+}
+
+// histogramVec counts observations into buckets by the value of a label.
+type histogramVec struct {
+	mutex   sync.Mutex
+	buckets []float64
+	counts  map[string][]uint64
+	sums    map[string]float64
+}
+
+func (h *histogramVec) Observe(label string, value float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	counts := h.counts[label]
+	if counts == nil {
+		// The last count is of every observation.
+		counts = make([]uint64, len(h.buckets)+1)
+		h.counts[label] = counts
+	}
+	for i, bucket := range h.buckets {
+		if value <= bucket {
+			counts[i]++
+		}
+	}
+	counts[len(h.buckets)]++
+	h.sums[label] += value
+}
+
+var (
+	updatesTotal   = &counterVec{values: map[string]float64{}}
+	telegramErrors = &counterVec{values: map[string]float64{}}
+	handsTotal     = &counterVec{values: map[string]float64{}}
+	handlerLatency = &histogramVec{
+		buckets: latencyBuckets,
+		counts:  map[string][]uint64{},
+		sums:    map[string]float64{},
+	}
+)
+
+// Live state which is not counted.
+var (
+	metricsMutex sync.Mutex
+	// Chips of every table in play, by chat.
+	tableChips = map[int64]int64{}
+	// Times hands ended in the last minute.
+	recentHands []time.Time
+	// Times of the last successful poll and update.
+	lastPoll   time.Time
+	lastUpdate time.Time
+	// Money in wallets and when it was summed.
+	walletsMoney int64
+	walletsTime  time.Time
+)
+
+// telegramTransport counts failed calls of the Bot API by method and
+// remembers the last successful poll.
+type telegramTransport struct {
+	http.RoundTripper
+}
+
+func (t *telegramTransport) RoundTrip(r *http.Request) (*http.Response,
+	error) {
+	resp, err := t.RoundTripper.RoundTrip(r)
+	if !strings.HasPrefix(r.URL.Path, "/bot") {
+		return resp, err
+	}
+	method := path.Base(r.URL.Path)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		telegramErrors.Add(method, 1)
+	} else if method == "getUpdates" {
+		metricsMutex.Lock()
+		lastPoll = time.Now()
+		metricsMutex.Unlock()
+	}
+	return resp, err
+}
+
+// Count the update. It goes first in handlers.
+func metricsHandler(e *Bot, update *Update) error {
+	kind := "other"
+	if update.Message != nil {
+		kind = "message"
+	} else if update.CallbackQuery != nil {
+		kind = "callback_query"
+	}
+	updatesTotal.Add(kind, 1)
+	metricsMutex.Lock()
+	lastUpdate = time.Now()
+	metricsMutex.Unlock()
+	return nil
+}
+
+// Record how long a handler took since start.
+func observeHandler(handler string, start time.Time) {
+	handlerLatency.Observe(handler, time.Since(start).Seconds())
+}
+
+// Remember the chips in play at the table of a chat, nil if the game has
+// ended. Bets of a running hand are still in play.
+func observeTable(chatID int64, game *Texas) {
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	if game == nil {
+		delete(tableChips, chatID)
+		return
+	}
+	chip := int64(0)
+	for i := 0; i < 10; i++ {
+		if game.Players[i] != nil {
+			chip += game.Players[i].Chip
+		}
+		if game.Round != nil && game.Round.Stage < End {
+			chip += game.Round.TotalBets[i]
+		}
+	}
+	tableChips[chatID] = chip
+}
+
+// Count a hand which has ended.
+func observeHand() {
+	handsTotal.Add("", 1)
+	now := time.Now()
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	recentHands = append(recentHands, now)
+	for len(recentHands) > 0 && now.Sub(recentHands[0]) > time.Minute {
+		recentHands = recentHands[1:]
+	}
+}
+
+// Sum the money in wallets, or use the last sum if it is recent.
+func getWalletsMoney() (int64, error) {
+	metricsMutex.Lock()
+	if time.Since(walletsTime) < walletsCacheAge {
+		defer metricsMutex.Unlock()
+		return walletsMoney, nil
+	}
+	metricsMutex.Unlock()
+	money, err := store.TotalBalance()
+	if err != nil {
+		return 0, err
+	}
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+	walletsMoney = money
+	walletsTime = time.Now()
+	return money, nil
+}
+
+// Ping the store and return how long it took.
+func pingStore() (time.Duration, error) {
+	start := time.Now()
+	err := store.Ping()
+	return time.Since(start), err
+}
+
+func writeHeader(w io.Writer, name string, kind string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// Write a value of every label, sorted by label.
+func writeLabeled(w io.Writer, name string, label string,
+	values map[string]float64) {
+	labels := make([]string, 0, len(values))
+	for value := range values {
+		labels = append(labels, value)
+	}
+	sort.Strings(labels)
+	for _, value := range labels {
+		if label == "" {
+			fmt.Fprintf(w, "%s %v\n", name, values[value])
+		} else {
+			fmt.Fprintf(w, "%s{%s=%q} %v\n", name, label, value, values[value])
+		}
+	}
+}
+
+func (c *counterVec) Write(w io.Writer, name string, label string,
+	help string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	writeHeader(w, name, "counter", help)
+	writeLabeled(w, name, label, c.values)
+}
+
+func (h *histogramVec) Write(w io.Writer, name string, label string,
+	help string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	writeHeader(w, name, "histogram", help)
+	labels := make([]string, 0, len(h.counts))
+	for value := range h.counts {
+		labels = append(labels, value)
+	}
+	sort.Strings(labels)
+	for _, value := range labels {
+		counts := h.counts[value]
+		for i, bucket := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket{%s=%q,le=\"%v\"} %d\n", name, label,
+				value, bucket, counts[i])
+		}
+		total := counts[len(h.buckets)]
+		fmt.Fprintf(w, "%s_bucket{%s=%q,le=\"+Inf\"} %d\n", name, label,
+			value, total)
+		fmt.Fprintf(w, "%s_sum{%s=%q} %v\n", name, label, value,
+			h.sums[value])
+		fmt.Fprintf(w, "%s_count{%s=%q} %d\n", name, label, value, total)
+	}
+}
+
+func writeGauge(w io.Writer, name string, help string, value float64) {
+	writeHeader(w, name, "gauge", help)
+	fmt.Fprintf(w, "%s %v\n", name, value)
+}
+
+// Serve the metrics in the Prometheus text format.
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	updatesTotal.Write(w, "texas_updates_total", "type",
+		"Updates processed by type.")
+	handlerLatency.Write(w, "texas_handler_duration_seconds", "handler",
+		"Time to handle an update on its table.")
+	telegramErrors.Write(w, "texas_telegram_errors_total", "method",
+		"Failed calls of the Telegram Bot API by method.")
+	handsTotal.Write(w, "texas_hands_total", "", "Hands played.")
+	metricsMutex.Lock()
+	tables := len(tableChips)
+	chip := int64(0)
+	for _, c := range tableChips {
+		chip += c
+	}
+	hands := 0
+	for _, t := range recentHands {
+		if time.Since(t) <= time.Minute {
+			hands++
+		}
+	}
+	metricsMutex.Unlock()
+	writeGauge(w, "texas_active_tables", "Tables with a game.",
+		float64(tables))
+	writeGauge(w, "texas_hands_per_minute", "Hands ended in the last minute.",
+		float64(hands))
+	writeHeader(w, "texas_chips", "gauge",
+		"Chips in play at tables and money in wallets.")
+	fmt.Fprintf(w, "texas_chips{where=\"tables\"} %d\n", chip)
+	money, err := getWalletsMoney()
+	if err != nil {
+		log.Println("Error:", err, "< handleMetrics")
+	} else {
+		fmt.Fprintf(w, "texas_chips{where=\"wallets\"} %d\n", money)
+	}
+	latency, err := pingStore()
+	up := 1
+	if err != nil {
+		up = 0
+	}
+	writeGauge(w, "texas_redis_up", "Whether Redis answers a ping.",
+		float64(up))
+	writeGauge(w, "texas_redis_latency_seconds", "Time Redis took to ping.",
+		latency.Seconds())
+}
+
+// Check Redis and the last successful poll.
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	problems := make([]string, 0)
+	_, err := pingStore()
+	if err != nil {
+		problems = append(problems, "redis: "+err.Error())
+	}
+	metricsMutex.Lock()
+	poll := lastPoll
+	update := lastUpdate
+	metricsMutex.Unlock()
+	// Webhooks wait for Telegram, so only polls are checked.
+	if config.Bot().WebhookURL == "" && time.Since(poll) > healthPollAge {
+		problems = append(problems, fmt.Sprint("no successful poll for ",
+			time.Since(poll)))
+	}
+	if len(problems) > 0 {
+		http.Error(w, strings.Join(problems, "\n"),
+			http.StatusServiceUnavailable)
+		return
+	}
+	text := "ok"
+	if !update.IsZero() {
+		text += fmt.Sprint("\nlast update ", time.Since(update), " ago")
+	}
+	fmt.Fprintln(w, text)
+}
+
+// Count calls of the Bot API and serve /metrics and /healthz at
+// MetricsListen.
+func runMetrics() {
+	http.DefaultTransport = &telegramTransport{http.DefaultTransport}
+	// Polls have not started yet.
+	lastPoll = time.Now()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", handleMetrics)
+	mux.HandleFunc("/healthz", handleHealth)
+	go func() {
+		err := http.ListenAndServe(config.Bot().MetricsListen, mux)
+		panic("Error: " + err.Error())
+	}()
+	log.Println("Serving metrics at", config.Bot().MetricsListen)
+}
@@ -0,0 +1,67 @@
+package texas
+
+import (
+	"encoding/json"
+	"log"
+
+	. "github.com/magicae/telegram-bot"
+)
+
+// Save the table of a group, or forget it if the game has ended.
+func saveGame(chatID int64) error {
+	game := getGame(chatID)
+	observeTable(chatID, game)
+	publishTable(chatID, game)
+	if game == nil {
+		return store.DeleteTable(chatID)
+	}
+	data, err := json.Marshal(game)
+	if err != nil {
+		return err
+	}
+	return store.SaveTable(chatID, data)
+}
+
+// Load tables saved by the last run and tell every group about it.
+func loadGames(e *Bot) {
+	chatIDs, err := store.Tables()
+	if err != nil {
+		panic("Error: " + err.Error())
+	}
+	for _, chatID := range chatIDs {
+		data, err := store.LoadTable(chatID)
+		if err != nil {
+			log.Println("Error:", err, "< loadGames")
+			continue
+		}
+		game := &Texas{}
+		err = json.Unmarshal(data, game)
+		if err != nil {
+			log.Println("Error:", err, "< loadGames")
+			store.DeleteTable(chatID)
+			continue
+		}
+		game.Bot = e
+		// Tables saved before blinds were configurable.
+		if game.BigBlind == 0 {
+			game.SmallBlind, game.BigBlind = 50, 100
+		}
+		setGame(chatID, game)
+		observeTable(chatID, game)
+		log.Println("Restored table", chatID)
+		_, err = e.SendMessage(&SendMessageRequest{
+			ChatID: chatID,
+			Text: tr(getLanguage(chatID),
+				"Table restored! Sorry for the interruption."),
+		})
+		if err != nil {
+			log.Println("Error:", err, "< loadGames")
+		}
+		if game.Round != nil && game.Round.Stage < End {
+			err = game.ShowStatus()
+			if err != nil {
+				log.Println("Error:", err, "< loadGames")
+			}
+		}
+	}
+}
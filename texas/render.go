@@ -0,0 +1,473 @@
+package texas
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// CardTheme is the look of table and card images.
+type CardTheme struct {
+	Background color.RGBA
+	Felt       color.RGBA
+	Seat       color.RGBA
+	Text       color.RGBA
+	CardFace   color.RGBA
+	CardBack   color.RGBA
+	// Suits are colors of diamonds, hearts, clubs and spades.
+	Suits  [4]color.RGBA
+	Button color.RGBA
+	Actor  color.RGBA
+}
+
+var cardThemes = map[string]*CardTheme{
+	"classic": &CardTheme{
+		Background: color.RGBA{0x3b, 0x23, 0x14, 0xff},
+		Felt:       color.RGBA{0x1e, 0x6b, 0x3a, 0xff},
+		Seat:       color.RGBA{0x10, 0x30, 0x1c, 0xff},
+		Text:       color.RGBA{0xff, 0xff, 0xff, 0xff},
+		CardFace:   color.RGBA{0xff, 0xff, 0xff, 0xff},
+		CardBack:   color.RGBA{0x1d, 0x3f, 0x8f, 0xff},
+		Suits: [4]color.RGBA{
+			color.RGBA{0xd0, 0x10, 0x10, 0xff},
+			color.RGBA{0xd0, 0x10, 0x10, 0xff},
+			color.RGBA{0x10, 0x10, 0x10, 0xff},
+			color.RGBA{0x10, 0x10, 0x10, 0xff},
+		},
+		Button: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Actor:  color.RGBA{0xff, 0xc8, 0x00, 0xff},
+	},
+	"dark": &CardTheme{
+		Background: color.RGBA{0x12, 0x12, 0x12, 0xff},
+		Felt:       color.RGBA{0x26, 0x2b, 0x33, 0xff},
+		Seat:       color.RGBA{0x3a, 0x40, 0x4a, 0xff},
+		Text:       color.RGBA{0xe0, 0xe0, 0xe0, 0xff},
+		CardFace:   color.RGBA{0xe8, 0xe8, 0xe8, 0xff},
+		CardBack:   color.RGBA{0x6a, 0x1b, 0x1b, 0xff},
+		Suits: [4]color.RGBA{
+			color.RGBA{0xc0, 0x20, 0x20, 0xff},
+			color.RGBA{0xc0, 0x20, 0x20, 0xff},
+			color.RGBA{0x20, 0x20, 0x20, 0xff},
+			color.RGBA{0x20, 0x20, 0x20, 0xff},
+		},
+		Button: color.RGBA{0xe0, 0xe0, 0xe0, 0xff},
+		Actor:  color.RGBA{0x4f, 0xc3, 0xf7, 0xff},
+	},
+	"four-color": &CardTheme{
+		Background: color.RGBA{0x3b, 0x23, 0x14, 0xff},
+		Felt:       color.RGBA{0x1e, 0x6b, 0x3a, 0xff},
+		Seat:       color.RGBA{0x10, 0x30, 0x1c, 0xff},
+		Text:       color.RGBA{0xff, 0xff, 0xff, 0xff},
+		CardFace:   color.RGBA{0xff, 0xff, 0xff, 0xff},
+		CardBack:   color.RGBA{0x1d, 0x3f, 0x8f, 0xff},
+		Suits: [4]color.RGBA{
+			color.RGBA{0x10, 0x4f, 0xd0, 0xff},
+			color.RGBA{0xd0, 0x10, 0x10, 0xff},
+			color.RGBA{0x10, 0x90, 0x30, 0xff},
+			color.RGBA{0x10, 0x10, 0x10, 0xff},
+		},
+		Button: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Actor:  color.RGBA{0xff, 0xc8, 0x00, 0xff},
+	},
+}
+
+// Sizes of images in pixels.
+const (
+	tableWidth  = 960
+	tableHeight = 600
+	cardWidth   = 70
+	cardHeight  = 100
+	cardGap     = 10
+	seatWidth   = 200
+	seatHeight  = 70
+)
+
+// Glyphs of the 5x7 font, "#" for a pixel.
+var fontGlyphs = map[rune][7]string{
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'A': {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B': {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C': {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D': {"###..", "#..#.", "#...#", "#...#", "#...#", "#..#.", "###.."},
+	'E': {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F': {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G': {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H': {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I': {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J': {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K': {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L': {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M': {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N': {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P': {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q': {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R': {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S': {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T': {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U': {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V': {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W': {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X': {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y': {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z': {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'$': {"..#..", ".####", "#.#..", ".###.", "..#.#", "####.", "..#.."},
+	'+': {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'#': {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+	'/': {".....", "....#", "...#.", "..#..", ".#...", "#....", "....."},
+	'.': {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	'?': {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+	'@': {".###.", "#...#", "#.###", "#.#.#", "#.###", "#....", ".###."},
+	'_': {".....", ".....", ".....", ".....", ".....", ".....", "#####"},
+	':': {".....", ".##..", ".##..", ".....", ".##..", ".##..", "....."},
+	'[': {".###.", ".#...", ".#...", ".#...", ".#...", ".#...", ".###."},
+	']': {".###.", "...#.", "...#.", "...#.", "...#.", "...#.", ".###."},
+	'(': {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')': {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+	'*': {".....", "#.#.#", ".###.", "#####", ".###.", "#.#.#", "....."},
+	'!': {"..#..", "..#..", "..#..", "..#..", "..#..", ".....", "..#.."},
+	' ': {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+}
+
+// Glyphs of diamonds, hearts, clubs and spades.
+var suitGlyphs = [4][7]string{
+	{"...#...", "..###..", ".#####.", "#######", ".#####.", "..###..", "...#..."},
+	{".##.##.", "#######", "#######", "#######", ".#####.", "..###..", "...#..."},
+	{"..###..", "..###..", "#######", "#######", "##.#.##", "...#...", "..###.."},
+	{"...#...", "..###..", ".#####.", "#######", "#######", "...#...", "..###.."},
+}
+
+var cardRankTexts = [15]string{"", "", "2", "3", "4", "5", "6", "7", "8",
+	"9", "10", "J", "Q", "K", "A"}
+
+// Card art loaded from config.Bot().CardArt, by the card notation or "back".
+var (
+	cardArt      map[string]image.Image
+	cardArtMutex sync.Mutex
+)
+
+func getCardTheme() *CardTheme {
+	if theme, ok := cardThemes[config.Bot().CardTheme]; ok {
+		return theme
+	}
+	return cardThemes["classic"]
+}
+
+// Names of the card themes in order.
+func getCardThemes() []string {
+	names := make([]string, 0, len(cardThemes))
+	for name := range cardThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get the art of the card, e.g. "As.png" in config.Bot().CardArt, or nil to
+// draw the card.
+func getCardArt(name string) image.Image {
+	if config.Bot().CardArt == "" {
+		return nil
+	}
+	cardArtMutex.Lock()
+	defer cardArtMutex.Unlock()
+	if cardArt == nil {
+		cardArt = map[string]image.Image{}
+		names := []string{"back"}
+		for suit := 0; suit < 4; suit++ {
+			for rank := 2; rank <= 14; rank++ {
+				names = append(names, getPokerNotation(&PokerCard{suit, rank}))
+			}
+		}
+		for _, name := range names {
+			file, err := os.Open(filepath.Join(config.Bot().CardArt, name+".png"))
+			if err != nil {
+				continue
+			}
+			img, err := png.Decode(file)
+			file.Close()
+			if err != nil {
+				log.Println("Error:", err, "< getCardArt", name)
+				continue
+			}
+			cardArt[name] = img
+		}
+	}
+	return cardArt[name]
+}
+
+func fillRect(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{c}, image.ZP, draw.Src)
+}
+
+// Draw a rectangle border of width w.
+func strokeRect(img draw.Image, r image.Rectangle, w int, c color.Color) {
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+w), c)
+	fillRect(img, image.Rect(r.Min.X, r.Max.Y-w, r.Max.X, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+w, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Max.X-w, r.Min.Y, r.Max.X, r.Max.Y), c)
+}
+
+func fillEllipse(img draw.Image, center image.Point, rx int, ry int,
+	c color.Color) {
+	for y := -ry; y <= ry; y++ {
+		dy := float64(y) / float64(ry)
+		dx := int(float64(rx) * math.Sqrt(1-dy*dy))
+		fillRect(img, image.Rect(center.X-dx, center.Y+y, center.X+dx,
+			center.Y+y+1), c)
+	}
+}
+
+// Draw a bitmap glyph with each pixel as a scale x scale square.
+func drawGlyph(img draw.Image, x int, y int, glyph []string, scale int,
+	c color.Color) {
+	for row, line := range glyph {
+		for col, pixel := range line {
+			if pixel == '#' {
+				fillRect(img, image.Rect(x+col*scale, y+row*scale,
+					x+(col+1)*scale, y+(row+1)*scale), c)
+			}
+		}
+	}
+}
+
+// Width of the text drawn by drawText.
+func textWidth(text string, scale int) int {
+	return len([]rune(text)) * 6 * scale
+}
+
+// Draw the text at (x, y). Letters are drawn in upper case and characters
+// out of the font as "?".
+func drawText(img draw.Image, x int, y int, text string, scale int,
+	c color.Color) {
+	for _, r := range strings.ToUpper(text) {
+		glyph, ok := fontGlyphs[r]
+		if !ok {
+			glyph = fontGlyphs['?']
+		}
+		drawGlyph(img, x, y, glyph[:], scale, c)
+		x += 6 * scale
+	}
+}
+
+// Whether the font has every character of the text.
+func canDraw(text string) bool {
+	for _, r := range strings.ToUpper(text) {
+		if _, ok := fontGlyphs[r]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Translate a label of the image. Languages the font can not draw keep the
+// English text.
+func trImage(lang string, text string) string {
+	if translated := tr(lang, text); canDraw(translated) {
+		return translated
+	}
+	return text
+}
+
+// Name of a player in the seat numbered count. Names the font can not draw
+// fall back to the username, or the number alone.
+func seatName(count int, player *TexasPlayer) string {
+	name := "[" + strconv.Itoa(count) + "]"
+	if player.DisplayName != "" && canDraw(player.DisplayName) {
+		name += " " + player.DisplayName
+	} else if player.Username != "" {
+		name += " @" + player.Username
+	}
+	if len([]rune(name)) > 15 {
+		name = string([]rune(name)[:15])
+	}
+	return name
+}
+
+// Draw the text centered at x.
+func drawTextCentered(img draw.Image, x int, y int, text string, scale int,
+	c color.Color) {
+	drawText(img, x-textWidth(text, scale)/2, y, text, scale, c)
+}
+
+// Draw the image scaled into the rectangle.
+func drawScaled(img draw.Image, r image.Rectangle, src image.Image) {
+	b := src.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, src.At(b.Min.X+(x-r.Min.X)*b.Dx()/r.Dx(),
+				b.Min.Y+(y-r.Min.Y)*b.Dy()/r.Dy()))
+		}
+	}
+}
+
+// Draw the card at (x, y), or its back if card is nil.
+func drawCard(img draw.Image, x int, y int, card *PokerCard,
+	theme *CardTheme) {
+	r := image.Rect(x, y, x+cardWidth, y+cardHeight)
+	name := "back"
+	if card != nil {
+		name = getPokerNotation(card)
+	}
+	if art := getCardArt(name); art != nil {
+		drawScaled(img, r, art)
+		return
+	}
+	if card == nil {
+		fillRect(img, r, theme.CardBack)
+		strokeRect(img, r, 4, theme.CardFace)
+		return
+	}
+	fillRect(img, r, theme.CardFace)
+	strokeRect(img, r, 1, color.Black)
+	c := theme.Suits[card.Suit]
+	drawText(img, x+5, y+5, cardRankTexts[card.Rank], 3, c)
+	drawGlyph(img, x+5, y+30, suitGlyphs[card.Suit][:], 2, c)
+	drawGlyph(img, x+cardWidth-40, y+cardHeight-40, suitGlyphs[card.Suit][:],
+		5, c)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	err := png.Encode(buffer, img)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Center of the seat around the table. Seat 0 is at the bottom and the
+// others follow clockwise.
+func seatCenter(seat int) image.Point {
+	angle := math.Pi/2 + float64(seat)*math.Pi/5
+	return image.Pt(tableWidth/2+int(380*math.Cos(angle)),
+		tableHeight/2+int(230*math.Sin(angle)))
+}
+
+// Render the table with the board, the pot and everyone's chips as a PNG.
+// Labels are in the language lang.
+func (t *Texas) RenderTable(lang string) ([]byte, error) {
+	if t.Round == nil {
+		return nil, errors.New("Round is not ready.")
+	}
+	theme := getCardTheme()
+	img := image.NewRGBA(image.Rect(0, 0, tableWidth, tableHeight))
+	fillRect(img, img.Bounds(), theme.Background)
+	center := image.Pt(tableWidth/2, tableHeight/2)
+	fillEllipse(img, center, 400, 240, theme.Seat)
+	fillEllipse(img, center, 390, 230, theme.Felt)
+	drawTextCentered(img, center.X, 220,
+		trImage(lang, "Pot")+" $"+strconv.FormatInt(t.Round.Pot, 10), 3,
+		theme.Text)
+	x := center.X - (5*cardWidth+4*cardGap)/2
+	for i := 0; i < 5; i++ {
+		drawCard(img, x, 260, t.Round.CommunityCards[i], theme)
+		x += cardWidth + cardGap
+	}
+	count := 0
+	for i := 0; i < 10; i++ {
+		if t.Round.UserState[i] == Out {
+			continue
+		}
+		count++
+		player := t.Players[i]
+		p := seatCenter(i)
+		r := image.Rect(p.X-seatWidth/2, p.Y-seatHeight/2, p.X+seatWidth/2,
+			p.Y+seatHeight/2)
+		fillRect(img, r, theme.Seat)
+		if i == t.Round.ActorIndex && t.Round.Stage != End {
+			strokeRect(img, r, 4, theme.Actor)
+		}
+		drawText(img, r.Min.X+8, r.Min.Y+8, seatName(count, player), 2,
+			theme.Text)
+		drawText(img, r.Min.X+8, r.Min.Y+28,
+			"$"+strconv.FormatInt(player.Chip, 10), 2, theme.Text)
+		status := ""
+		if t.Round.UserState[i] == Fold {
+			status = trImage(lang, "FOLD")
+		} else if player.Chip <= 0 {
+			status = trImage(lang, "ALL IN")
+		} else if t.Round.StageBets[i] > 0 {
+			status = "+" + strconv.FormatInt(t.Round.StageBets[i], 10)
+		}
+		drawText(img, r.Min.X+8, r.Min.Y+48, status, 2, theme.Actor)
+		if i == t.Round.Dealer {
+			button := image.Pt(r.Max.X-20, r.Max.Y-20)
+			fillEllipse(img, button, 14, 14, theme.Button)
+			drawTextCentered(img, button.X+1, button.Y-7, "D", 2, color.Black)
+		}
+	}
+	return encodePNG(img)
+}
+
+// Render the hole cards next to the board as a PNG.
+func renderHand(hole [2]*PokerCard, board [5]*PokerCard) ([]byte, error) {
+	theme := getCardTheme()
+	step := cardWidth + cardGap
+	img := image.NewRGBA(image.Rect(0, 0, 7*step+3*cardGap,
+		cardHeight+2*cardGap))
+	fillRect(img, img.Bounds(), theme.Felt)
+	x := cardGap
+	for _, card := range hole {
+		drawCard(img, x, cardGap, card, theme)
+		x += step
+	}
+	x += 2 * cardGap
+	fillRect(img, image.Rect(x-cardGap-2, 0, x-cardGap+2, img.Bounds().Max.Y),
+		theme.Seat)
+	for _, card := range board {
+		drawCard(img, x, cardGap, card, theme)
+		x += step
+	}
+	return encodePNG(img)
+}
+
+// Send the image as a photo, or the text if it fails.
+func sendImage(e *Bot, chatID int64, data []byte, err error, caption string,
+	text string) {
+	if err == nil {
+		err = sendPhoto(chatID, "cards.png", data, caption)
+		if err == nil {
+			return
+		}
+	}
+	log.Println("Error:", err, "< sendImage")
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID: chatID,
+		Text:   text,
+	})
+	if err != nil {
+		log.Println("Error:", err, "< sendImage")
+	}
+}
+
+// Send the table image to the chat.
+func (t *Texas) SendTableImage(chatID int64) {
+	data, err := t.RenderTable(getLanguage(chatID))
+	header := t.StatusHeader()
+	sendImage(t.Bot, chatID, data, err, header, header)
+}
@@ -0,0 +1,23 @@
+package texas
+
+import "testing"
+
+func TestSeatNameFallsBack(t *testing.T) {
+	for _, test := range []struct {
+		player *TexasPlayer
+		want   string
+	}{
+		{&TexasPlayer{DisplayName: "Alice", Username: "alice"}, "[2] Alice"},
+		{&TexasPlayer{DisplayName: "小明", Username: "xiaoming"}, "[2] @xiaoming"},
+		{&TexasPlayer{DisplayName: "小明"}, "[2]"},
+		{&TexasPlayer{DisplayName: "Bartholomew Smith"}, "[2] Bartholomew"},
+	} {
+		if name := seatName(2, test.player); name != test.want {
+			t.Errorf("Seat of %q is %q, want %q", test.player.DisplayName,
+				name, test.want)
+		}
+	}
+	if label := trImage("zh", "FOLD"); label != "FOLD" {
+		t.Errorf("Label the font can not draw is %q", label)
+	}
+}
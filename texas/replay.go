@@ -0,0 +1,190 @@
+package texas
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+)
+
+// Delay between two steps of a replay.
+const replayDelay = 2 * time.Second
+
+func handleReplay(e *Bot, id int, chat *Chat, user *User, args string) error {
+	lang := getLanguage(chat.ID)
+	reply := func(text string) error {
+		_, err := e.SendMessage(&SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             text,
+			ReplyToMessageID: id,
+		})
+		return err
+	}
+	handID, err := strconv.ParseInt(strings.TrimPrefix(args, "#"), 10, 64)
+	if err != nil {
+		return reply(tr(lang, "Usage: /replay <hand id>"))
+	}
+	record, err := loadHand(handID)
+	if err != nil {
+		return reply(tr(lang, err.Error()))
+	}
+	if record.ChatID != chat.ID &&
+		!(chat.Type == "private" && record.Played(user.ID)) {
+		return reply(tr(lang, "You can only replay hands of this group or "+
+			"your own hands in the private chat."))
+	}
+	err = reply(trf(lang, "Replaying hand #%d...", record.ID))
+	if err != nil {
+		return err
+	}
+	go replayHand(e, chat.ID, record)
+	return nil
+}
+
+// Whether the user played the hand.
+func (r *HandRecord) Played(userID int) bool {
+	for _, seat := range r.Seats {
+		if seat.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Count community cards dealt before the stage ends.
+func countCommunityCards(stage int) int {
+	if stage < Flop {
+		return 0
+	}
+	if stage > River {
+		return 5
+	}
+	return stage - Preflop + 2
+}
+
+// Send the hand step by step with the same layout as the live hand.
+func replayHand(e *Bot, chatID int64, record *HandRecord) {
+	t := NewTexas(e, chatID, 0)
+	t.Dealer = record.Dealer
+	t.Round = &Round{
+		Dealer:     record.Dealer,
+		Stage:      CompulsoryBets,
+		ActorIndex: -1,
+	}
+	for _, seat := range record.Seats {
+		t.Players[seat.Seat] = &TexasPlayer{
+			UserID:      seat.UserID,
+			DisplayName: seat.DisplayName,
+			Chip:        seat.Chip,
+		}
+		t.Round.UserState[seat.Seat] = InGame
+	}
+	send := func(text string) {
+		_, err := e.SendMessage(&SendMessageRequest{
+			ChatID: chatID,
+			Text:   text,
+		})
+		if err != nil {
+			log.Println("Error:", err, "< replayHand")
+		}
+		time.Sleep(replayDelay)
+	}
+	// Move to the stage and deal community cards of it.
+	moveTo := func(stage int) {
+		for t.Round.Stage < stage {
+			t.Round.Stage++
+			for i := 0; i < 10; i++ {
+				t.Round.StageBets[i] = 0
+			}
+			from := countCommunityCards(t.Round.Stage - 1)
+			to := countCommunityCards(t.Round.Stage)
+			if to > len(record.Board) {
+				to = len(record.Board)
+			}
+			for i := from; i < to; i++ {
+				t.Round.CommunityCards[i] = record.Board[i]
+			}
+			if from < to {
+				t.SendBoard(record.Board[from:to]...)
+			}
+		}
+	}
+	lang := t.Lang()
+	text := trf(lang, "= REPLAY #%d =", record.ID) + "\n"
+	var max int64 = 0
+	for i, action := range record.Actions {
+		if action.Stage > t.Round.Stage {
+			moveTo(action.Stage)
+			max = 0
+		}
+		t.Players[action.Seat].Chip -= action.Amount
+		t.Round.StageBets[action.Seat] = action.To
+		t.Round.TotalBets[action.Seat] += action.Amount
+		t.Round.Pot += action.Amount
+		if action.Action == ActionFold {
+			t.Round.UserState[action.Seat] = Fold
+		}
+		text += t.Players[action.Seat].DisplayName + " " +
+			action.Text(lang, max) + "\n"
+		if action.To > max {
+			max = action.To
+		}
+		// Blinds are shown together.
+		if action.Action == ActionSmallBlind {
+			continue
+		}
+		t.Round.ActorIndex = -1
+		if i+1 < len(record.Actions) &&
+			record.Actions[i+1].Stage == t.Round.Stage {
+			t.Round.ActorIndex = record.Actions[i+1].Seat
+		}
+		send(text + t.StatusHeader() + t.StatusPlayers())
+		text = ""
+	}
+	// Everyone is all in or folds.
+	if len(record.Board) > countCommunityCards(t.Round.Stage) {
+		moveTo(River)
+	}
+	shown := false
+	for _, seat := range record.Seats {
+		if seat.Fold {
+			t.Round.UserState[seat.Seat] = Fold
+		}
+		if seat.Hand >= 0 {
+			shown = true
+			t.Round.PlayerCards[seat.Seat] = seat.Cards
+			t.Round.TopCards[seat.Seat] = getTopCards(t.Round.CommunityCards,
+				seat.Cards)
+		}
+	}
+	if shown {
+		send(t.ShowdownText())
+	}
+	text = trf(lang, "= END OF #%d = Pot: %d", record.ID, record.Pot) + "\n"
+	count := 0
+	for _, seat := range record.Seats {
+		count++
+		text += fmt.Sprintf("[%d] %s", count, seat.DisplayName)
+		win := seat.Earn - t.Round.TotalBets[seat.Seat]
+		if seat.Fold {
+			text += " " + tr(lang, "FOLD")
+		} else if win >= 0 {
+			text += " " + trf(lang, "WIN +%d", win)
+		} else {
+			text += " " + tr(lang, "LOSE")
+		}
+		chip := t.Players[seat.Seat].Chip + seat.Earn
+		text += " " + trn(lang, chip, "-> %d chip.", "-> %d chips.", chip) +
+			"\n"
+	}
+	_, err := e.SendMessage(&SendMessageRequest{
+		ChatID: chatID,
+		Text:   text,
+	})
+	if err != nil {
+		log.Println("Error:", err, "< replayHand")
+	}
+}
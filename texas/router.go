@@ -0,0 +1,336 @@
+package texas
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// Command is a command of the bot.
+type Command struct {
+	Name    string
+	Aliases []string
+	// Shorthands are words that run the command without a slash in groups.
+	Shorthands []string
+	Args       string
+	Usage      string
+	// Where the command works.
+	Group   bool
+	Private bool
+	// Table commands act in the hand and wait while the table is paused.
+	Table  bool
+	Handle func(e *Bot, message *Message, args string) error
+}
+
+// Commands in the order of the help.
+var commands []*Command
+
+// Handlers return errUsage for bad arguments.
+var errUsage = errors.New("usage")
+
+// Adapt a handler without arguments.
+func withoutArgs(h func(*Bot, int, *Chat, *User) error) func(*Bot, *Message,
+	string) error {
+	return func(e *Bot, message *Message, args string) error {
+		return h(e, message.MessageID, message.Chat, message.From)
+	}
+}
+
+// Adapt a handler with arguments.
+func withArgs(h func(*Bot, int, *Chat, *User, string) error) func(*Bot,
+	*Message, string) error {
+	return func(e *Bot, message *Message, args string) error {
+		return h(e, message.MessageID, message.Chat, message.From, args)
+	}
+}
+
+// Adapt a handler taking an amount.
+func withAmount(h func(*Bot, int64, int, *Chat, *User) error) func(*Bot,
+	*Message, string) error {
+	return func(e *Bot, message *Message, args string) error {
+		n, err := strconv.ParseInt(args, 10, 64)
+		if err != nil {
+			return errUsage
+		}
+		return h(e, n, message.MessageID, message.Chat, message.From)
+	}
+}
+
+func init() {
+	commands = []*Command{
+		{Name: "new", Usage: "start a new game", Group: true,
+			Handle: withoutArgs(handleNewGame)},
+		{Name: "join", Usage: "join the game", Group: true,
+			Handle: withoutArgs(handleJoin)},
+		{Name: "leave", Usage: "leave the game with your chips", Group: true,
+			Handle: withoutArgs(handleLeave)},
+		{Name: "list", Usage: "list players", Group: true,
+			Handle: withoutArgs(handleList)},
+		{Name: "startgame", Usage: "deal a new hand", Group: true,
+			Handle: withoutArgs(handleStartRound)},
+		{Name: "check", Shorthands: []string{"x"}, Usage: "check",
+			Group: true, Table: true, Handle: withoutArgs(handleCheck)},
+		{Name: "call", Shorthands: []string{"c"}, Usage: "call", Group: true,
+			Table: true, Handle: withoutArgs(handleCall)},
+		{Name: "raise", Aliases: []string{"r"}, Args: "[amount]",
+			Usage: "raise by the amount, or show the raise sizes",
+			Group: true, Table: true, Handle: func(e *Bot, message *Message,
+				args string) error {
+				if args == "" {
+					return withoutArgs(handleRaise)(e, message, args)
+				}
+				return withAmount(handleRaiseN)(e, message, args)
+			}},
+		{Name: "raiseto", Args: "<total>",
+			Usage: "raise to the total bet of this stage", Group: true,
+			Table: true, Handle: withAmount(handleRaiseTo)},
+		{Name: "pot", Shorthands: []string{"pot"}, Usage: "raise the pot",
+			Group: true, Table: true, Handle: withoutArgs(handlePot)},
+		{Name: "allin", Usage: "go all-in", Group: true, Table: true,
+			Handle: withoutArgs(handleAllIn)},
+		{Name: "fold", Shorthands: []string{"f"}, Usage: "fold", Group: true,
+			Table: true, Handle: withoutArgs(handleFold)},
+		{Name: "start", Usage: "register to play", Private: true,
+			Handle: withoutArgs(handlePrivateStart)},
+		{Name: "web", Usage: "get a token to play on the web", Private: true,
+			Handle: withoutArgs(handleWeb)},
+		{Name: "getmoney", Usage: "claim the daily money", Group: true,
+			Private: true, Handle: withoutArgs(handleGetMoney)},
+		{Name: "wallet", Usage: "show your money", Group: true, Private: true,
+			Handle: withoutArgs(handleWallet)},
+		{Name: "give", Args: "[@user] <amount>",
+			Usage: "give money to a user, or to the one you reply to",
+			Group: true, Private: true, Handle: func(e *Bot, message *Message,
+				args string) error {
+				var target *User
+				if message.ReplyToMessage != nil {
+					target = message.ReplyToMessage.From
+				}
+				return handleGive(e, message.MessageID, message.Chat,
+					message.From, target, args)
+			}},
+		{Name: "confirm", Usage: "confirm your transfer", Group: true,
+			Private: true, Handle: withoutArgs(handleConfirmGive)},
+		{Name: "cancel", Usage: "cancel your transfer", Group: true,
+			Private: true, Handle: withoutArgs(handleCancelGive)},
+		{Name: "ledger", Usage: "show your wallet history", Group: true,
+			Private: true, Handle: withoutArgs(handleLedger)},
+		{Name: "stats", Args: "[@user]", Usage: "show player statistics",
+			Group: true, Private: true, Handle: withArgs(handleStats)},
+		{Name: "top", Args: "[global]", Usage: "show the leaderboards",
+			Group: true, Private: true, Handle: withArgs(handleTop)},
+		{Name: "export", Args: "[hands]",
+			Usage: "send your hand history in PokerStars format", Group: true,
+			Private: true, Handle: withArgs(handleExport)},
+		{Name: "replay", Args: "<hand id>", Usage: "replay a hand",
+			Group: true, Private: true, Handle: withArgs(handleReplay)},
+		{Name: "audit", Args: "<@user>", Usage: "check a wallet (admins)",
+			Group: true, Private: true, Handle: withArgs(handleAudit)},
+		{Name: "lang", Args: "[code]",
+			Usage: "show or set the language of this chat", Group: true,
+			Private: true, Handle: withArgs(handleLanguage)},
+		{Name: "cards", Args: "[style]",
+			Usage: "show or set how cards are shown in this chat", Group: true,
+			Private: true, Handle: withArgs(handleCards)},
+		{Name: "kick", Args: "<@user>",
+			Usage: "fold a player and send the chips back (table owners)",
+			Group: true, Handle: func(e *Bot, message *Message,
+				args string) error {
+				var target *User
+				if message.ReplyToMessage != nil {
+					target = message.ReplyToMessage.From
+				}
+				return handleKick(e, message.MessageID, message.Chat,
+					message.From, target, args)
+			}},
+		{Name: "pause", Usage: "pause the table (table owners)", Group: true,
+			Handle: withoutArgs(handlePause)},
+		{Name: "resume", Usage: "resume the table (table owners)",
+			Group: true, Handle: withoutArgs(handleResume)},
+		{Name: "public",
+			Usage: "let web players find and join the table (table owners)",
+			Group: true, Handle: withoutArgs(handlePublic)},
+		{Name: "private",
+			Usage: "hide the table from web players (table owners)",
+			Group: true, Handle: withoutArgs(handlePrivate)},
+		{Name: "endgame",
+			Usage: "end the game and send everyone's chips back (table owners)",
+			Group: true, Handle: withoutArgs(handleEndGame)},
+		{Name: "help", Usage: "show this help", Group: true, Private: true,
+			Handle: func(e *Bot, message *Message, args string) error {
+				return replyUsage(e, message, "")
+			}},
+	}
+}
+
+// Find a command by its name or alias.
+func findCommand(name string) *Command {
+	for _, command := range commands {
+		if command.Name == name {
+			return command
+		}
+		for _, alias := range command.Aliases {
+			if alias == name {
+				return command
+			}
+		}
+	}
+	return nil
+}
+
+// Find a command by its shorthand.
+func findShorthand(word string) *Command {
+	for _, command := range commands {
+		for _, shorthand := range command.Shorthands {
+			if shorthand == word {
+				return command
+			}
+		}
+	}
+	return nil
+}
+
+func isGroup(chat *Chat) bool {
+	return chat.Type == "group" || chat.Type == "supergroup"
+}
+
+// Whether the command works in the chat.
+func (c *Command) WorksIn(chat *Chat) bool {
+	if isGroup(chat) {
+		return c.Group
+	}
+	return c.Private
+}
+
+// Help of the command, e.g. "/raise [amount] - raise by the amount".
+func (c *Command) Help(lang string) string {
+	text := "/" + c.Name
+	if c.Args != "" {
+		text += " " + c.Args
+	}
+	for _, alias := range c.Aliases {
+		text += ", /" + alias
+	}
+	for _, shorthand := range c.Shorthands {
+		if shorthand != c.Name {
+			text += ", " + shorthand
+		}
+	}
+	return text + " - " + tr(lang, c.Usage)
+}
+
+// Reply the commands working in the chat after the text, which is
+// translated.
+func replyUsage(e *Bot, message *Message, text string) error {
+	lang := getLanguage(message.Chat.ID)
+	for _, command := range commands {
+		if command.WorksIn(message.Chat) {
+			text += "\n" + command.Help(lang)
+		}
+	}
+	_, err := e.SendMessage(&SendMessageRequest{
+		ChatID:           message.Chat.ID,
+		Text:             strings.TrimPrefix(text, "\n"),
+		ReplyToMessageID: message.MessageID,
+	})
+	return err
+}
+
+// Reply if the table of the group is paused.
+func replyPaused(e *Bot, message *Message) bool {
+	game := getGame(message.Chat.ID)
+	if game == nil || !game.Paused {
+		return false
+	}
+	_, err := e.SendMessage(&SendMessageRequest{
+		ChatID:           message.Chat.ID,
+		Text:             tr(getLanguage(message.Chat.ID), ErrTablePaused.Error()),
+		ReplyToMessageID: message.MessageID,
+	})
+	if err != nil {
+		log.Println("Error:", err, "< replyPaused")
+	}
+	return true
+}
+
+// Run the command of a message, if it has one.
+func routeCommand(e *Bot, message *Message) error {
+	lang := getLanguage(message.Chat.ID)
+	text := strings.TrimSpace(message.Text)
+	name, args := text, ""
+	if i := strings.IndexAny(text, " \n"); i >= 0 {
+		name, args = text[:i], strings.TrimSpace(text[i+1:])
+	}
+	toBot := message.ReplyToMessage != nil &&
+		message.ReplyToMessage.From != nil &&
+		message.ReplyToMessage.From.ID == config.Bot().ID
+	if !strings.HasPrefix(name, "/") {
+		if !isGroup(message.Chat) || args != "" {
+			return nil
+		}
+		// A bare number raises only as a reply to the bot.
+		n, err := strconv.ParseInt(name, 10, 64)
+		if err == nil {
+			if toBot {
+				if replyPaused(e, message) {
+					return nil
+				}
+				return handleRaiseN(e, n, message.MessageID, message.Chat,
+					message.From)
+			}
+			return nil
+		}
+		command := findShorthand(strings.ToLower(name))
+		if command == nil || command.Table && replyPaused(e, message) {
+			return nil
+		}
+		return command.Handle(e, message, "")
+	}
+	name = strings.ToLower(name[1:])
+	mentioned := false
+	if i := strings.Index(name, "@"); i >= 0 {
+		// The command is for another bot.
+		if name[i+1:] != strings.ToLower(config.Bot().Username) {
+			return nil
+		}
+		name, mentioned = name[:i], true
+	}
+	command := findCommand(name)
+	if command == nil {
+		// Other bots in the group may know it.
+		if isGroup(message.Chat) && !mentioned && !toBot {
+			return nil
+		}
+		return replyUsage(e, message, trf(lang,
+			"Unknown command /%s. Try one of these:", name))
+	}
+	if !command.WorksIn(message.Chat) {
+		text := trf(lang, "/%s only works in the private chat.",
+			command.Name)
+		if command.Group {
+			text = trf(lang, "/%s only works in groups.", command.Name)
+		}
+		_, err := e.SendMessage(&SendMessageRequest{
+			ChatID:           message.Chat.ID,
+			Text:             text,
+			ReplyToMessageID: message.MessageID,
+		})
+		return err
+	}
+	if command.Table && replyPaused(e, message) {
+		return nil
+	}
+	err := command.Handle(e, message, args)
+	if err == errUsage {
+		_, err = e.SendMessage(&SendMessageRequest{
+			ChatID:           message.Chat.ID,
+			Text:             tr(lang, "Usage:") + " " + command.Help(lang),
+			ReplyToMessageID: message.MessageID,
+		})
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package texas
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// 1 once the bot is shutting down and takes no more commands.
+var shuttingDown int32
+
+// Whether the bot is shutting down.
+func isShuttingDown() bool {
+	return atomic.LoadInt32(&shuttingDown) == 1
+}
+
+// Wait for SIGTERM or an interrupt.
+func waitForSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+}
+
+// Cancel the hand of a group, cash everyone out and tell the group.
+func settleTable(e *Bot, chatID int64) {
+	game := getGame(chatID)
+	if game == nil {
+		return
+	}
+	lang := game.Lang()
+	text := endGame(game)
+	err := saveGame(chatID)
+	if err != nil {
+		log.Println("Error:", err, "< settleTable")
+	}
+	log.Println("Settled table", chatID)
+	_, err = e.SendMessage(&SendMessageRequest{
+		ChatID: chatID,
+		Text: tr(lang, "The bot is going down for maintenance. "+
+			"Everyone took the chips back:") + "\n" + text,
+	})
+	if err != nil {
+		log.Println("Error:", err, "< settleTable")
+	}
+}
+
+// Settle the table after the commands queued before, retrying while its
+// queue is full.
+func settleOnTable(e *Bot, chatID int64) error {
+	deadline := time.Now().Add(config.Bot().TableTimeout)
+	for {
+		done, err := sendTable(chatID, func() {
+			settleTable(e, chatID)
+		})
+		if err == ErrTableBusy && time.Now().Before(deadline) {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		return waitTable(done)
+	}
+}
+
+// Stop taking commands, then settle every table. Hands being played are
+// cancelled and their bets returned, and every seated stack goes back to
+// its wallet.
+func shutdown(e *Bot) {
+	atomic.StoreInt32(&shuttingDown, 1)
+	log.Println("Shutting down")
+	// Tables without a game may have commands queued which start one.
+	tablesMutex.Lock()
+	chatIDs := make([]int64, 0, len(tables))
+	for chatID := range tables {
+		chatIDs = append(chatIDs, chatID)
+	}
+	tablesMutex.Unlock()
+	var wait sync.WaitGroup
+	for _, chatID := range chatIDs {
+		wait.Add(1)
+		go func(chatID int64) {
+			defer wait.Done()
+			err := settleOnTable(e, chatID)
+			if err != nil {
+				// The table is saved and restored on the next start.
+				log.Println("Error:", err, "< shutdown", chatID)
+			}
+		}(chatID)
+	}
+	wait.Wait()
+	log.Println("Settled every table")
+}
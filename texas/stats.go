@@ -0,0 +1,192 @@
+package texas
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	. "github.com/magicae/telegram-bot"
+)
+
+// Fields of player statistics
+const (
+	StatHands           = "hands"
+	StatVPIP            = "vpip"
+	StatPFR             = "pfr"
+	StatThreeBetChances = "3bet_chances"
+	StatThreeBets       = "3bets"
+	StatBets            = "bets"
+	StatCalls           = "calls"
+	StatSawFlop         = "saw_flop"
+	StatShowdowns       = "showdowns"
+	StatShowdownWins    = "showdown_wins"
+	StatNet             = "net"
+)
+
+// Count statistics of every player from a finished hand.
+func getHandStats(record *HandRecord) map[int]map[string]int64 {
+	stats := map[int]map[string]int64{}
+	bets := map[int]int64{}
+	for _, seat := range record.Seats {
+		stats[seat.Seat] = map[string]int64{StatHands: 1}
+	}
+	vpip := map[int]bool{}
+	pfr := map[int]bool{}
+	threeBetChance := map[int]bool{}
+	threeBet := map[int]bool{}
+	folded := map[int]bool{}
+	raises := 0
+	for _, action := range record.Actions {
+		stat := stats[action.Seat]
+		if stat == nil {
+			continue
+		}
+		bets[action.Seat] += action.Amount
+		if action.Stage == Preflop {
+			if raises == 1 {
+				threeBetChance[action.Seat] = true
+			}
+			switch action.Action {
+			case ActionCall:
+				vpip[action.Seat] = true
+			case ActionBet, ActionRaise:
+				vpip[action.Seat] = true
+				pfr[action.Seat] = true
+				if raises == 1 {
+					threeBet[action.Seat] = true
+				}
+				raises++
+			}
+		}
+		switch action.Action {
+		case ActionBet, ActionRaise:
+			stat[StatBets]++
+		case ActionCall:
+			stat[StatCalls]++
+		case ActionFold:
+			if action.Stage < Flop {
+				folded[action.Seat] = true
+			}
+		}
+	}
+	for _, seat := range record.Seats {
+		stat := stats[seat.Seat]
+		if vpip[seat.Seat] {
+			stat[StatVPIP] = 1
+		}
+		if pfr[seat.Seat] {
+			stat[StatPFR] = 1
+		}
+		if threeBetChance[seat.Seat] {
+			stat[StatThreeBetChances] = 1
+		}
+		if threeBet[seat.Seat] {
+			stat[StatThreeBets] = 1
+		}
+		if len(record.Board) >= 3 && !folded[seat.Seat] {
+			stat[StatSawFlop] = 1
+		}
+		if seat.Hand >= 0 {
+			stat[StatShowdowns] = 1
+			if seat.Earn > bets[seat.Seat] {
+				stat[StatShowdownWins] = 1
+			}
+		}
+		stat[StatNet] = seat.Earn - bets[seat.Seat]
+	}
+	return stats
+}
+
+// Add statistics of a finished hand to every player, in the group and
+// globally.
+func saveStats(record *HandRecord, stats map[int]map[string]int64) {
+	for _, seat := range record.Seats {
+		for _, chatID := range []int64{record.ChatID, 0} {
+			err := store.AddStats(seat.UserID, chatID, stats[seat.Seat])
+			if err != nil {
+				log.Println("Error: ", err, "< saveStats")
+			}
+		}
+	}
+}
+
+func formatPercent(n, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
+
+func formatStats(stats map[string]int64) []string {
+	af := "-"
+	if stats[StatCalls] > 0 {
+		af = fmt.Sprintf("%.2f",
+			float64(stats[StatBets])/float64(stats[StatCalls]))
+	}
+	net := fmt.Sprintf("%d", stats[StatNet])
+	if stats[StatNet] > 0 {
+		net = "+" + net
+	}
+	return []string{
+		fmt.Sprintf("%d", stats[StatHands]),
+		formatPercent(stats[StatVPIP], stats[StatHands]),
+		formatPercent(stats[StatPFR], stats[StatHands]),
+		formatPercent(stats[StatThreeBets], stats[StatThreeBetChances]),
+		af,
+		formatPercent(stats[StatShowdowns], stats[StatSawFlop]),
+		formatPercent(stats[StatShowdownWins], stats[StatShowdowns]),
+		net,
+	}
+}
+
+var statNames = []string{"Hands", "VPIP", "PFR", "3-Bet", "AF", "WTSD",
+	"W$SD", "Net"}
+
+func handleStats(e *Bot, id int, chat *Chat, user *User, args string) error {
+	lang := getLanguage(chat.ID)
+	reply := func(text string) error {
+		_, err := e.SendMessage(&SendMessageRequest{
+			ChatID:           chat.ID,
+			Text:             text,
+			ReplyToMessageID: id,
+		})
+		return err
+	}
+	userID := user.ID
+	name := getUserDisplayName(user)
+	if strings.HasPrefix(args, "@") {
+		var err error
+		userID, err = store.UserByName(args[1:])
+		if err != nil {
+			return reply(tr(lang, err.Error()))
+		}
+		name = args
+	}
+	global, err := store.Stats(userID, 0)
+	if err != nil {
+		return err
+	}
+	if global[StatHands] == 0 {
+		return reply(trf(lang, "%s has not played any hand yet.", name))
+	}
+	globalTexts := formatStats(global)
+	text := trf(lang, "Stats of %s", name)
+	if chat.Type == "group" || chat.Type == "supergroup" {
+		group, err := store.Stats(userID, chat.ID)
+		if err != nil {
+			return err
+		}
+		groupTexts := formatStats(group)
+		text += " " + tr(lang, "(this group / all groups)") + "\n"
+		for i, statName := range statNames {
+			text += tr(lang, statName) + ": " + groupTexts[i] + " / " +
+				globalTexts[i] + "\n"
+		}
+	} else {
+		text += "\n"
+		for i, statName := range statNames {
+			text += tr(lang, statName) + ": " + globalTexts[i] + "\n"
+		}
+	}
+	return reply(text)
+}
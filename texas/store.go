@@ -0,0 +1,154 @@
+package texas
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotRegistered = errors.New("You need /start in the private chat at first!")
+	ErrNoMoney       = errors.New("You don't have enough money.")
+	ErrNoTable       = errors.New("Table is not found.")
+	ErrNoHand        = errors.New("Hand is not found.")
+	ErrNoUser        = errors.New("User is not found.")
+	ErrNameTaken     = errors.New("The name is taken.")
+)
+
+// Store keeps everything that should outlive a single process: wallets,
+// private chat registrations and table state.
+type Store interface {
+	// Balance returns the money in the user's wallet.
+	Balance(userID int) (int64, error)
+	// Credit adds money to the user's wallet and returns the new balance.
+	// The change is written to the ledger with the reason in entry.
+	Credit(userID int, amount int64, entry LedgerEntry) (int64, error)
+	// Debit takes money from the user's wallet and returns the new balance.
+	// It fails with ErrNoMoney if the wallet does not hold enough.
+	Debit(userID int, amount int64, entry LedgerEntry) (int64, error)
+	// Transfer moves money from a wallet to another in one step and returns
+	// the new balance of the sender. It fails with ErrNoMoney if the sender
+	// does not hold enough, or ErrGiveLimit if the sender would give more
+	// than limit on day.
+	Transfer(from int, to int, amount int64, limit int64, day string) (int64,
+		error)
+	// TotalBalance returns the money in every wallet.
+	TotalBalance() (int64, error)
+	// Ledger returns the last n changes of the user's wallet, the latest
+	// first, or all of them if n is 0.
+	Ledger(userID int, n int) ([]*LedgerEntry, error)
+
+	// ClaimDaily claims the daily money of today and returns how many days
+	// in a row it has been claimed. It fails with ErrClaimed if it has been
+	// claimed today.
+	ClaimDaily(userID int, today string, yesterday string) (int64, error)
+	// ClaimRelief claims the bankruptcy relief, which can be claimed once
+	// every cooldown. It fails with ErrClaimed and returns the time to wait
+	// if it is not ready.
+	ClaimRelief(userID int, cooldown time.Duration) (time.Duration, error)
+
+	// BuyIn moves up to max money from the user's wallet into the escrow of
+	// a table in one step and returns the chips bought. It fails with
+	// ErrNoMoney if the wallet is empty.
+	BuyIn(userID int, chatID int64, max int64) (int64, error)
+	// CashOut removes the user's escrow of a table and credits chip back to
+	// the wallet in one step. handID is the last hand played.
+	CashOut(userID int, chatID int64, chip int64, handID int64) error
+	// SetEscrow records the chips the user holds at a table.
+	SetEscrow(userID int, chatID int64, chip int64) error
+	// EscrowTables lists groups holding escrowed chips.
+	EscrowTables() ([]int64, error)
+	// Escrowed returns the chips the user holds at every table.
+	Escrowed(userID int) (int64, error)
+	// Refund returns every escrowed chip of a table to the wallets and
+	// removes the escrow. Returns the chips refunded to each user.
+	Refund(chatID int64) (map[int]int64, error)
+
+	// PrivateChat returns the private chat registered by /start.
+	PrivateChat(userID int) (int64, error)
+	// Register remembers the private chat of the user.
+	Register(userID int, chatID int64) error
+	// SignUp registers a user without Telegram by the username, with the
+	// private chat 0. They have negative IDs. Returns ErrNameTaken if a
+	// user has the username.
+	SignUp(username string) (int, error)
+	// Registered returns when the user registered for the first time, or
+	// the zero time if it is unknown. Users registered before the times
+	// were kept have a private chat but no time.
+	Registered(userID int) (time.Time, error)
+	// SetUsername remembers the username of the user.
+	SetUsername(userID int, username string) error
+	// UserByName finds a user by username.
+	UserByName(username string) (int, error)
+	// SetDisplayName remembers the display name of the user.
+	SetDisplayName(userID int, name string) error
+	// DisplayName returns the display name of the user.
+	DisplayName(userID int) (string, error)
+	// SetLanguage sets the language of a chat, or of a user by the ID.
+	SetLanguage(chatID int64, lang string) error
+	// Language returns the language of a chat, empty if it is not set.
+	Language(chatID int64) (string, error)
+	// SetCardStyle sets how cards are shown in a chat, or to a user by the ID.
+	SetCardStyle(chatID int64, style string) error
+	// CardStyle returns how cards are shown in a chat, empty if it is not set.
+	CardStyle(chatID int64) (string, error)
+
+	// LoadTable returns the saved state of the table in a group.
+	LoadTable(chatID int64) ([]byte, error)
+	// SaveTable saves the state of the table in a group.
+	SaveTable(chatID int64, data []byte) error
+	// DeleteTable removes the saved state of the table in a group.
+	DeleteTable(chatID int64) error
+	// Tables lists groups with a saved table.
+	Tables() ([]int64, error)
+
+	// NextHandID returns a new unique hand ID.
+	NextHandID() (int64, error)
+	// SaveHand saves the history of a hand played in a group by users.
+	SaveHand(handID int64, chatID int64, userIDs []int, data []byte) error
+	// LoadHand returns the history of a hand.
+	LoadHand(handID int64) ([]byte, error)
+	// UserHands returns IDs of the last n hands played by the user, the
+	// latest first.
+	UserHands(userID int, n int) ([]int64, error)
+	// TableHands returns IDs of the last n hands played in a group, the
+	// latest first.
+	TableHands(chatID int64, n int) ([]int64, error)
+
+	// AddStats adds to statistics of the user in a group, or globally if
+	// chatID is 0.
+	AddStats(userID int, chatID int64, stats map[string]int64) error
+	// Stats returns statistics of the user in a group, or globally if
+	// chatID is 0.
+	Stats(userID int, chatID int64) (map[string]int64, error)
+
+	// IncrRank adds delta to the score of the user on a leaderboard of a
+	// group, or the global one if chatID is 0. Returns the new score.
+	IncrRank(board string, chatID int64, userID int, delta float64) (float64,
+		error)
+	// SetRank sets the score of the user on a leaderboard.
+	SetRank(board string, chatID int64, userID int, score float64) error
+	// RemoveRank removes the user from a leaderboard.
+	RemoveRank(board string, chatID int64, userID int) error
+	// Ranks returns the top n users of a leaderboard.
+	Ranks(board string, chatID int64, n int) ([]*Rank, error)
+	// RankedChats lists groups with leaderboards.
+	RankedChats() ([]int64, error)
+	// Season returns the current season and when it started.
+	Season() (int64, time.Time, error)
+	// StartSeason archives every leaderboard under the current season and
+	// starts a new one. Season 0 is before seasons, so its leaderboards are
+	// kept for the first season.
+	StartSeason(season int64, start time.Time) error
+
+	// Ping checks the connection to the storage.
+	Ping() error
+}
+
+// Rank is a user's score on a leaderboard.
+type Rank struct {
+	UserID int
+	Score  float64
+}
+
+// Global storage
+var store Store
@@ -0,0 +1,546 @@
+package texas
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps data in process memory. It is used by tests and for
+// running the bot without Redis.
+type MemoryStore struct {
+	mutex      sync.Mutex
+	money      map[int]int64
+	ledgers    map[int][]*LedgerEntry
+	escrows    map[int64]map[int]int64
+	claims     map[int]string
+	streaks    map[int]int64
+	reliefs    map[int]time.Time
+	chats      map[int]int64
+	registered map[int]time.Time
+	given      map[int]map[string]int64
+	usernames  map[string]int
+	names      map[int]string
+	languages  map[int64]string
+	cardStyles map[int64]string
+	stats      map[int]map[int64]map[string]int64
+	tables     map[int64][]byte
+	handID     int64
+	webUserID  int64
+	hands      map[int64][]byte
+	userHands  map[int][]int64
+	tableHands map[int64][]int64
+	ranks      map[string]map[int]float64
+	ranked     map[int64]bool
+	seasons    map[string]map[int]float64
+	season     int64
+	start      time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		money:      map[int]int64{},
+		ledgers:    map[int][]*LedgerEntry{},
+		escrows:    map[int64]map[int]int64{},
+		claims:     map[int]string{},
+		streaks:    map[int]int64{},
+		reliefs:    map[int]time.Time{},
+		chats:      map[int]int64{},
+		registered: map[int]time.Time{},
+		given:      map[int]map[string]int64{},
+		usernames:  map[string]int{},
+		names:      map[int]string{},
+		languages:  map[int64]string{},
+		cardStyles: map[int64]string{},
+		stats:      map[int]map[int64]map[string]int64{},
+		ranks:      map[string]map[int]float64{},
+		ranked:     map[int64]bool{},
+		seasons:    map[string]map[int]float64{},
+		tables:     map[int64][]byte{},
+		hands:      map[int64][]byte{},
+		userHands:  map[int][]int64{},
+		tableHands: map[int64][]int64{},
+	}
+}
+
+func (s *MemoryStore) Balance(userID int) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.money[userID], nil
+}
+
+func (s *MemoryStore) TotalBalance() (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	total := int64(0)
+	for _, money := range s.money {
+		total += money
+	}
+	return total, nil
+}
+
+// Change the wallet and write it to the ledger. The caller holds the mutex.
+func (s *MemoryStore) change(userID int, amount int64, entry LedgerEntry) int64 {
+	s.money[userID] += amount
+	entry.Time = time.Now().Unix()
+	entry.Amount = amount
+	entry.Balance = s.money[userID]
+	s.ledgers[userID] = append(s.ledgers[userID], &entry)
+	return s.money[userID]
+}
+
+func (s *MemoryStore) Credit(userID int, amount int64,
+	entry LedgerEntry) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.change(userID, amount, entry), nil
+}
+
+func (s *MemoryStore) Debit(userID int, amount int64,
+	entry LedgerEntry) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.money[userID] < amount {
+		return s.money[userID], ErrNoMoney
+	}
+	return s.change(userID, -amount, entry), nil
+}
+
+func (s *MemoryStore) Transfer(from int, to int, amount int64, limit int64,
+	day string) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.money[from] < amount {
+		return 0, ErrNoMoney
+	}
+	if s.given[from] == nil {
+		s.given[from] = map[string]int64{}
+	}
+	if s.given[from][day]+amount > limit {
+		return 0, ErrGiveLimit
+	}
+	s.given[from][day] += amount
+	s.change(to, amount, LedgerEntry{Reason: LedgerReceive, Peer: from})
+	return s.change(from, -amount, LedgerEntry{Reason: LedgerGive, Peer: to}),
+		nil
+}
+
+func (s *MemoryStore) Ledger(userID int, n int) ([]*LedgerEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ledger := s.ledgers[userID]
+	entries := make([]*LedgerEntry, 0)
+	for i := len(ledger) - 1; i >= 0 && (n <= 0 || len(entries) < n); i-- {
+		entry := *ledger[i]
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+func (s *MemoryStore) ClaimDaily(userID int, today string,
+	yesterday string) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.claims[userID] == today {
+		return 0, ErrClaimed
+	}
+	if s.claims[userID] == yesterday {
+		s.streaks[userID]++
+	} else {
+		s.streaks[userID] = 1
+	}
+	s.claims[userID] = today
+	return s.streaks[userID], nil
+}
+
+func (s *MemoryStore) ClaimRelief(userID int,
+	cooldown time.Duration) (time.Duration, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if wait := s.reliefs[userID].Sub(time.Now()); wait > 0 {
+		return wait, ErrClaimed
+	}
+	s.reliefs[userID] = time.Now().Add(cooldown)
+	return 0, nil
+}
+
+func (s *MemoryStore) BuyIn(userID int, chatID int64, max int64) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	buy := min(s.money[userID], max)
+	if buy <= 0 {
+		return 0, ErrNoMoney
+	}
+	s.change(userID, -buy, LedgerEntry{Reason: LedgerBuyIn, ChatID: chatID})
+	if s.escrows[chatID] == nil {
+		s.escrows[chatID] = map[int]int64{}
+	}
+	s.escrows[chatID][userID] += buy
+	return buy, nil
+}
+
+func (s *MemoryStore) CashOut(userID int, chatID int64, chip int64,
+	handID int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.escrows[chatID], userID)
+	if len(s.escrows[chatID]) == 0 {
+		delete(s.escrows, chatID)
+	}
+	s.change(userID, chip, LedgerEntry{
+		Reason: LedgerCashOut,
+		ChatID: chatID,
+		HandID: handID,
+	})
+	return nil
+}
+
+func (s *MemoryStore) SetEscrow(userID int, chatID int64, chip int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.escrows[chatID] == nil {
+		s.escrows[chatID] = map[int]int64{}
+	}
+	s.escrows[chatID][userID] = chip
+	return nil
+}
+
+func (s *MemoryStore) EscrowTables() ([]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	chatIDs := make([]int64, 0, len(s.escrows))
+	for chatID := range s.escrows {
+		chatIDs = append(chatIDs, chatID)
+	}
+	return chatIDs, nil
+}
+
+func (s *MemoryStore) Escrowed(userID int) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	total := int64(0)
+	for _, escrow := range s.escrows {
+		total += escrow[userID]
+	}
+	return total, nil
+}
+
+func (s *MemoryStore) Refund(chatID int64) (map[int]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	refunds := map[int]int64{}
+	for userID, chip := range s.escrows[chatID] {
+		s.change(userID, chip, LedgerEntry{Reason: LedgerRefund, ChatID: chatID})
+		refunds[userID] = chip
+	}
+	delete(s.escrows, chatID)
+	return refunds, nil
+}
+
+func (s *MemoryStore) PrivateChat(userID int) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	chatID, ok := s.chats[userID]
+	if !ok {
+		return 0, ErrNotRegistered
+	}
+	return chatID, nil
+}
+
+func (s *MemoryStore) Register(userID int, chatID int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.chats[userID]; !ok {
+		s.registered[userID] = time.Now()
+	}
+	s.chats[userID] = chatID
+	return nil
+}
+
+func (s *MemoryStore) SignUp(username string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := strings.ToLower(username)
+	if _, ok := s.usernames[key]; ok {
+		return 0, ErrNameTaken
+	}
+	s.webUserID++
+	userID := -int(s.webUserID)
+	s.usernames[key] = userID
+	s.chats[userID] = 0
+	s.registered[userID] = time.Now()
+	return userID, nil
+}
+
+func (s *MemoryStore) Registered(userID int) (time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.registered[userID], nil
+}
+
+func (s *MemoryStore) SetUsername(userID int, username string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if username != "" {
+		s.usernames[strings.ToLower(username)] = userID
+	}
+	return nil
+}
+
+func (s *MemoryStore) UserByName(username string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	userID, ok := s.usernames[strings.ToLower(username)]
+	if !ok {
+		return 0, ErrNoUser
+	}
+	return userID, nil
+}
+
+func (s *MemoryStore) SetDisplayName(userID int, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.names[userID] = name
+	return nil
+}
+
+func (s *MemoryStore) DisplayName(userID int) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	name, ok := s.names[userID]
+	if !ok {
+		return "", ErrNoUser
+	}
+	return name, nil
+}
+
+func (s *MemoryStore) SetLanguage(chatID int64, lang string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.languages[chatID] = lang
+	return nil
+}
+
+func (s *MemoryStore) Language(chatID int64) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.languages[chatID], nil
+}
+
+func (s *MemoryStore) SetCardStyle(chatID int64, style string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cardStyles[chatID] = style
+	return nil
+}
+
+func (s *MemoryStore) CardStyle(chatID int64) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cardStyles[chatID], nil
+}
+
+func (s *MemoryStore) LoadTable(chatID int64) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	data, ok := s.tables[chatID]
+	if !ok {
+		return nil, ErrNoTable
+	}
+	return data, nil
+}
+
+func (s *MemoryStore) SaveTable(chatID int64, data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tables[chatID] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *MemoryStore) DeleteTable(chatID int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.tables, chatID)
+	return nil
+}
+
+func (s *MemoryStore) Tables() ([]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	chatIDs := make([]int64, 0, len(s.tables))
+	for chatID := range s.tables {
+		chatIDs = append(chatIDs, chatID)
+	}
+	return chatIDs, nil
+}
+
+func (s *MemoryStore) NextHandID() (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handID++
+	return s.handID, nil
+}
+
+func (s *MemoryStore) SaveHand(handID int64, chatID int64, userIDs []int,
+	data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.hands[handID] = append([]byte(nil), data...)
+	s.tableHands[chatID] = append(s.tableHands[chatID], handID)
+	for _, userID := range userIDs {
+		s.userHands[userID] = append(s.userHands[userID], handID)
+	}
+	return nil
+}
+
+func (s *MemoryStore) LoadHand(handID int64) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	data, ok := s.hands[handID]
+	if !ok {
+		return nil, ErrNoHand
+	}
+	return data, nil
+}
+
+func (s *MemoryStore) UserHands(userID int, n int) ([]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return lastHands(s.userHands[userID], n), nil
+}
+
+func (s *MemoryStore) TableHands(chatID int64, n int) ([]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return lastHands(s.tableHands[chatID], n), nil
+}
+
+// lastHands returns the last n hand IDs, the latest first.
+func lastHands(handIDs []int64, n int) []int64 {
+	hands := make([]int64, 0, n)
+	for i := len(handIDs) - 1; i >= 0 && len(hands) < n; i-- {
+		hands = append(hands, handIDs[i])
+	}
+	return hands
+}
+
+func (s *MemoryStore) AddStats(userID int, chatID int64,
+	stats map[string]int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.stats[userID] == nil {
+		s.stats[userID] = map[int64]map[string]int64{}
+	}
+	if s.stats[userID][chatID] == nil {
+		s.stats[userID][chatID] = map[string]int64{}
+	}
+	for field, value := range stats {
+		s.stats[userID][chatID][field] += value
+	}
+	return nil
+}
+
+func (s *MemoryStore) Stats(userID int, chatID int64) (map[string]int64,
+	error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stats := map[string]int64{}
+	for field, value := range s.stats[userID][chatID] {
+		stats[field] = value
+	}
+	return stats, nil
+}
+
+func (s *MemoryStore) IncrRank(board string, chatID int64, userID int,
+	delta float64) (float64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := rankKey(board, chatID)
+	if s.ranks[key] == nil {
+		s.ranks[key] = map[int]float64{}
+	}
+	if chatID != 0 {
+		s.ranked[chatID] = true
+	}
+	s.ranks[key][userID] += delta
+	return s.ranks[key][userID], nil
+}
+
+func (s *MemoryStore) SetRank(board string, chatID int64, userID int,
+	score float64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := rankKey(board, chatID)
+	if s.ranks[key] == nil {
+		s.ranks[key] = map[int]float64{}
+	}
+	if chatID != 0 {
+		s.ranked[chatID] = true
+	}
+	s.ranks[key][userID] = score
+	return nil
+}
+
+func (s *MemoryStore) RemoveRank(board string, chatID int64, userID int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.ranks[rankKey(board, chatID)], userID)
+	return nil
+}
+
+func (s *MemoryStore) Ranks(board string, chatID int64, n int) ([]*Rank,
+	error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ranks := make([]*Rank, 0)
+	for userID, score := range s.ranks[rankKey(board, chatID)] {
+		ranks = append(ranks, &Rank{UserID: userID, Score: score})
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		if ranks[i].Score == ranks[j].Score {
+			return ranks[i].UserID > ranks[j].UserID
+		}
+		return ranks[i].Score > ranks[j].Score
+	})
+	if len(ranks) > n {
+		ranks = ranks[:n]
+	}
+	return ranks, nil
+}
+
+func (s *MemoryStore) RankedChats() ([]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	chatIDs := make([]int64, 0, len(s.ranked))
+	for chatID := range s.ranked {
+		chatIDs = append(chatIDs, chatID)
+	}
+	return chatIDs, nil
+}
+
+func (s *MemoryStore) Season() (int64, time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.season, s.start, nil
+}
+
+func (s *MemoryStore) StartSeason(season int64, start time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if season > 0 {
+		for key, ranks := range s.ranks {
+			s.seasons[strconv.FormatInt(season, 10)+":"+key] = ranks
+		}
+		s.ranks = map[string]map[int]float64{}
+		s.ranked = map[int64]bool{}
+	}
+	s.season = season + 1
+	s.start = start
+	return nil
+}
+
+func (s *MemoryStore) Ping() error {
+	return nil
+}
@@ -0,0 +1,778 @@
+package texas
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/redis.v5"
+)
+
+// RedisStore keeps data in Redis under texas:* keys.
+type RedisStore struct {
+	Client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{Client: client}
+}
+
+func moneyKey(userID int) string {
+	return "texas:user:" + strconv.Itoa(userID) + ":money"
+}
+
+func ledgerKey(userID int) string {
+	return "texas:user:" + strconv.Itoa(userID) + ":ledger"
+}
+
+func claimKey(userID int) string {
+	return "texas:user:" + strconv.Itoa(userID) + ":claim"
+}
+
+func reliefKey(userID int) string {
+	return "texas:user:" + strconv.Itoa(userID) + ":relief"
+}
+
+func givenKey(userID int) string {
+	return "texas:user:" + strconv.Itoa(userID) + ":given"
+}
+
+func registeredKey(userID int) string {
+	return "texas:user:" + strconv.Itoa(userID) + ":registered"
+}
+
+func chatKey(userID int) string {
+	return "texas:user:" + strconv.Itoa(userID) + ":chat"
+}
+
+func usernameKey(username string) string {
+	return "texas:username:" + strings.ToLower(username)
+}
+
+func statsKey(userID int, chatID int64) string {
+	key := "texas:user:" + strconv.Itoa(userID) + ":stats"
+	if chatID != 0 {
+		key += ":" + strconv.FormatInt(chatID, 10)
+	}
+	return key
+}
+
+func languageKey(chatID int64) string {
+	return "texas:chat:" + strconv.FormatInt(chatID, 10) + ":lang"
+}
+
+func cardStyleKey(chatID int64) string {
+	return "texas:chat:" + strconv.FormatInt(chatID, 10) + ":cards"
+}
+
+func nameKey(userID int) string {
+	return "texas:user:" + strconv.Itoa(userID) + ":name"
+}
+
+func rankKey(board string, chatID int64) string {
+	key := "texas:top:" + board
+	if chatID != 0 {
+		key += ":" + strconv.FormatInt(chatID, 10)
+	}
+	return key
+}
+
+func seasonRankKey(season int64, board string, chatID int64) string {
+	return "texas:season:" + strconv.FormatInt(season, 10) + ":" +
+		strings.TrimPrefix(rankKey(board, chatID), "texas:")
+}
+
+func tableKey(chatID int64) string {
+	return "texas:table:" + strconv.FormatInt(chatID, 10)
+}
+
+func escrowKey(chatID int64) string {
+	return "texas:table:" + strconv.FormatInt(chatID, 10) + ":escrow"
+}
+
+func handKey(handID int64) string {
+	return "texas:hand:" + strconv.FormatInt(handID, 10)
+}
+
+func userHandsKey(userID int) string {
+	return "texas:user:" + strconv.Itoa(userID) + ":hands"
+}
+
+func tableHandsKey(chatID int64) string {
+	return "texas:table:" + strconv.FormatInt(chatID, 10) + ":hands"
+}
+
+const (
+	tablesKey  = "texas:tables"
+	escrowsKey = "texas:escrows"
+	handIDKey  = "texas:hand:id"
+	webUserKey = "texas:webuser:id"
+	rankedKey  = "texas:top:chats"
+	seasonKey  = "texas:season"
+	// How many hands are listed for a user or a group.
+	maxHands = 1000
+)
+
+// Append an entry to a ledger with the amount and the balance after.
+const ledgerLua = `
+local function ledger(key, entry, amount, balance)
+	entry = cjson.decode(entry)
+	entry.Amount = amount
+	entry.Balance = balance
+	redis.call('RPUSH', key, cjson.encode(entry))
+end
+`
+
+// KEYS: money, ledger. ARGV: amount, entry.
+var creditScript = redis.NewScript(ledgerLua + `
+local balance = redis.call('INCRBY', KEYS[1], ARGV[1])
+ledger(KEYS[2], ARGV[2], tonumber(ARGV[1]), balance)
+return balance
+`)
+
+// KEYS: money, ledger. ARGV: amount, entry.
+var debitScript = redis.NewScript(ledgerLua + `
+local money = tonumber(redis.call('GET', KEYS[1]) or '0')
+local amount = tonumber(ARGV[1])
+if money < amount then
+	return -1
+end
+local balance = redis.call('DECRBY', KEYS[1], amount)
+ledger(KEYS[2], ARGV[2], -amount, balance)
+return balance
+`)
+
+// KEYS: from money, to money, given, from ledger, to ledger.
+// ARGV: amount, limit, day, from entry, to entry.
+var transferScript = redis.NewScript(ledgerLua + `
+local money = tonumber(redis.call('GET', KEYS[1]) or '0')
+local amount = tonumber(ARGV[1])
+if money < amount then
+	return -1
+end
+local given = tonumber(redis.call('HGET', KEYS[3], ARGV[3]) or '0')
+if given + amount > tonumber(ARGV[2]) then
+	return -2
+end
+redis.call('HINCRBY', KEYS[3], ARGV[3], amount)
+redis.call('EXPIRE', KEYS[3], 172800)
+local balance = redis.call('DECRBY', KEYS[1], amount)
+ledger(KEYS[4], ARGV[4], -amount, balance)
+ledger(KEYS[5], ARGV[5], amount, redis.call('INCRBY', KEYS[2], amount))
+return balance
+`)
+
+// KEYS: claim. ARGV: today, yesterday.
+var claimScript = redis.NewScript(`
+local day = redis.call('HGET', KEYS[1], 'day')
+if day == ARGV[1] then
+	return 0
+end
+local streak = 1
+if day == ARGV[2] then
+	streak = tonumber(redis.call('HGET', KEYS[1], 'streak') or '0') + 1
+end
+redis.call('HMSET', KEYS[1], 'day', ARGV[1], 'streak', streak)
+return streak
+`)
+
+// KEYS: money, escrow, escrows, ledger. ARGV: user, max, table, entry.
+var buyInScript = redis.NewScript(ledgerLua + `
+local money = tonumber(redis.call('GET', KEYS[1]) or '0')
+local buy = math.min(money, tonumber(ARGV[2]))
+if buy <= 0 then
+	return 0
+end
+local balance = redis.call('DECRBY', KEYS[1], buy)
+redis.call('HINCRBY', KEYS[2], ARGV[1], buy)
+redis.call('SADD', KEYS[3], ARGV[3])
+ledger(KEYS[4], ARGV[4], -buy, balance)
+return buy
+`)
+
+// KEYS: money, escrow, escrows, ledger. ARGV: user, chip, table, entry.
+var cashOutScript = redis.NewScript(ledgerLua + `
+redis.call('HDEL', KEYS[2], ARGV[1])
+local balance = redis.call('INCRBY', KEYS[1], ARGV[2])
+if redis.call('HLEN', KEYS[2]) == 0 then
+	redis.call('SREM', KEYS[3], ARGV[3])
+end
+ledger(KEYS[4], ARGV[4], tonumber(ARGV[2]), balance)
+return balance
+`)
+
+// KEYS: escrow, escrows. ARGV: table, user key prefix, entry.
+var refundScript = redis.NewScript(ledgerLua + `
+local escrow = redis.call('HGETALL', KEYS[1])
+for i = 1, #escrow, 2 do
+	local user = ARGV[2] .. escrow[i]
+	local balance = redis.call('INCRBY', user .. ':money', escrow[i + 1])
+	ledger(user .. ':ledger', ARGV[3], tonumber(escrow[i + 1]), balance)
+end
+redis.call('DEL', KEYS[1])
+redis.call('SREM', KEYS[2], ARGV[1])
+return escrow
+`)
+
+func (s *RedisStore) Balance(userID int) (int64, error) {
+	money, err := s.Client.Get(moneyKey(userID)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return money, err
+}
+
+// Wallets are summed by scanning their keys, count keys at a time.
+const walletScanCount = 1000
+
+func (s *RedisStore) TotalBalance() (int64, error) {
+	total := int64(0)
+	cursor := uint64(0)
+	for {
+		keys, next, err := s.Client.Scan(cursor, "texas:user:*:money",
+			walletScanCount).Result()
+		if err != nil {
+			return 0, err
+		}
+		if len(keys) > 0 {
+			values, err := s.Client.MGet(keys...).Result()
+			if err != nil {
+				return 0, err
+			}
+			for _, value := range values {
+				text, _ := value.(string)
+				money, _ := strconv.ParseInt(text, 10, 64)
+				total += money
+			}
+		}
+		if next == 0 {
+			return total, nil
+		}
+		cursor = next
+	}
+}
+
+// Encode a ledger entry for scripts.
+func encodeLedgerEntry(entry LedgerEntry) string {
+	entry.Time = time.Now().Unix()
+	data, _ := json.Marshal(entry)
+	return string(data)
+}
+
+func (s *RedisStore) Credit(userID int, amount int64,
+	entry LedgerEntry) (int64, error) {
+	reply, err := creditScript.Run(s.Client,
+		[]string{moneyKey(userID), ledgerKey(userID)},
+		amount, encodeLedgerEntry(entry)).Result()
+	if err != nil {
+		return 0, err
+	}
+	money, _ := reply.(int64)
+	return money, nil
+}
+
+func (s *RedisStore) Debit(userID int, amount int64,
+	entry LedgerEntry) (int64, error) {
+	reply, err := debitScript.Run(s.Client,
+		[]string{moneyKey(userID), ledgerKey(userID)},
+		amount, encodeLedgerEntry(entry)).Result()
+	if err != nil {
+		return 0, err
+	}
+	money, _ := reply.(int64)
+	if money < 0 {
+		return 0, ErrNoMoney
+	}
+	return money, nil
+}
+
+func (s *RedisStore) ClaimDaily(userID int, today string,
+	yesterday string) (int64, error) {
+	reply, err := claimScript.Run(s.Client, []string{claimKey(userID)},
+		today, yesterday).Result()
+	if err != nil {
+		return 0, err
+	}
+	streak, _ := reply.(int64)
+	if streak == 0 {
+		return 0, ErrClaimed
+	}
+	return streak, nil
+}
+
+func (s *RedisStore) ClaimRelief(userID int,
+	cooldown time.Duration) (time.Duration, error) {
+	ok, err := s.Client.SetNX(reliefKey(userID), 1, cooldown).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		return 0, nil
+	}
+	wait, err := s.Client.TTL(reliefKey(userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+	return wait, ErrClaimed
+}
+
+func (s *RedisStore) BuyIn(userID int, chatID int64, max int64) (int64, error) {
+	reply, err := buyInScript.Run(s.Client,
+		[]string{moneyKey(userID), escrowKey(chatID), escrowsKey,
+			ledgerKey(userID)},
+		userID, max, chatID, encodeLedgerEntry(LedgerEntry{
+			Reason: LedgerBuyIn,
+			ChatID: chatID,
+		})).Result()
+	if err != nil {
+		return 0, err
+	}
+	buy, _ := reply.(int64)
+	if buy <= 0 {
+		return 0, ErrNoMoney
+	}
+	return buy, nil
+}
+
+func (s *RedisStore) CashOut(userID int, chatID int64, chip int64,
+	handID int64) error {
+	return cashOutScript.Run(s.Client,
+		[]string{moneyKey(userID), escrowKey(chatID), escrowsKey,
+			ledgerKey(userID)},
+		userID, chip, chatID, encodeLedgerEntry(LedgerEntry{
+			Reason: LedgerCashOut,
+			ChatID: chatID,
+			HandID: handID,
+		})).Err()
+}
+
+func (s *RedisStore) Transfer(from int, to int, amount int64, limit int64,
+	day string) (int64, error) {
+	reply, err := transferScript.Run(s.Client,
+		[]string{moneyKey(from), moneyKey(to), givenKey(from),
+			ledgerKey(from), ledgerKey(to)},
+		amount, limit, day,
+		encodeLedgerEntry(LedgerEntry{Reason: LedgerGive, Peer: to}),
+		encodeLedgerEntry(LedgerEntry{Reason: LedgerReceive, Peer: from}),
+	).Result()
+	if err != nil {
+		return 0, err
+	}
+	balance, _ := reply.(int64)
+	switch balance {
+	case -1:
+		return 0, ErrNoMoney
+	case -2:
+		return 0, ErrGiveLimit
+	}
+	return balance, nil
+}
+
+func (s *RedisStore) Ledger(userID int, n int) ([]*LedgerEntry, error) {
+	start := int64(-n)
+	if n <= 0 {
+		start = 0
+	}
+	items, err := s.Client.LRange(ledgerKey(userID), start, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*LedgerEntry, 0, len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		entry := &LedgerEntry{}
+		err = json.Unmarshal([]byte(items[i]), entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (s *RedisStore) SetEscrow(userID int, chatID int64, chip int64) error {
+	return s.Client.HSet(escrowKey(chatID), strconv.Itoa(userID), chip).Err()
+}
+
+func (s *RedisStore) EscrowTables() ([]int64, error) {
+	return s.members(escrowsKey)
+}
+
+func (s *RedisStore) Escrowed(userID int) (int64, error) {
+	chatIDs, err := s.members(escrowsKey)
+	if err != nil {
+		return 0, err
+	}
+	total := int64(0)
+	for _, chatID := range chatIDs {
+		chip, err := s.Client.HGet(escrowKey(chatID),
+			strconv.Itoa(userID)).Int64()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += chip
+	}
+	return total, nil
+}
+
+func (s *RedisStore) Refund(chatID int64) (map[int]int64, error) {
+	reply, err := refundScript.Run(s.Client,
+		[]string{escrowKey(chatID), escrowsKey},
+		chatID, "texas:user:", encodeLedgerEntry(LedgerEntry{
+			Reason: LedgerRefund,
+			ChatID: chatID,
+		})).Result()
+	if err != nil {
+		return nil, err
+	}
+	escrow, _ := reply.([]interface{})
+	refunds := map[int]int64{}
+	for i := 0; i+1 < len(escrow); i += 2 {
+		userID, err := strconv.Atoi(escrow[i].(string))
+		if err != nil {
+			return refunds, err
+		}
+		chip, err := strconv.ParseInt(escrow[i+1].(string), 10, 64)
+		if err != nil {
+			return refunds, err
+		}
+		refunds[userID] = chip
+	}
+	return refunds, nil
+}
+
+func (s *RedisStore) PrivateChat(userID int) (int64, error) {
+	chatID, err := s.Client.Get(chatKey(userID)).Int64()
+	if err == redis.Nil {
+		return 0, ErrNotRegistered
+	}
+	return chatID, err
+}
+
+func (s *RedisStore) Register(userID int, chatID int64) error {
+	// Users registered before the times were kept stay without one.
+	known, err := s.Client.Exists(chatKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	if !known {
+		err = s.Client.SetNX(registeredKey(userID), time.Now().Unix(),
+			0).Err()
+		if err != nil {
+			return err
+		}
+	}
+	return s.Client.Set(chatKey(userID), chatID, 0).Err()
+}
+
+func (s *RedisStore) SignUp(username string) (int, error) {
+	id, err := s.Client.Incr(webUserKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	userID := -int(id)
+	ok, err := s.Client.SetNX(usernameKey(username), userID, 0).Result()
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrNameTaken
+	}
+	return userID, s.Register(userID, 0)
+}
+
+func (s *RedisStore) Registered(userID int) (time.Time, error) {
+	registered, err := s.Client.Get(registeredKey(userID)).Int64()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(registered, 0), nil
+}
+
+func (s *RedisStore) SetUsername(userID int, username string) error {
+	if username == "" {
+		return nil
+	}
+	return s.Client.Set(usernameKey(username), userID, 0).Err()
+}
+
+func (s *RedisStore) UserByName(username string) (int, error) {
+	userID, err := s.Client.Get(usernameKey(username)).Int64()
+	if err == redis.Nil {
+		return 0, ErrNoUser
+	}
+	return int(userID), err
+}
+
+func (s *RedisStore) SetDisplayName(userID int, name string) error {
+	return s.Client.Set(nameKey(userID), name, 0).Err()
+}
+
+func (s *RedisStore) DisplayName(userID int) (string, error) {
+	name, err := s.Client.Get(nameKey(userID)).Result()
+	if err == redis.Nil {
+		return "", ErrNoUser
+	}
+	return name, err
+}
+
+func (s *RedisStore) SetLanguage(chatID int64, lang string) error {
+	return s.Client.Set(languageKey(chatID), lang, 0).Err()
+}
+
+func (s *RedisStore) Language(chatID int64) (string, error) {
+	lang, err := s.Client.Get(languageKey(chatID)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return lang, err
+}
+
+func (s *RedisStore) SetCardStyle(chatID int64, style string) error {
+	return s.Client.Set(cardStyleKey(chatID), style, 0).Err()
+}
+
+func (s *RedisStore) CardStyle(chatID int64) (string, error) {
+	style, err := s.Client.Get(cardStyleKey(chatID)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return style, err
+}
+
+func (s *RedisStore) LoadTable(chatID int64) ([]byte, error) {
+	data, err := s.Client.Get(tableKey(chatID)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNoTable
+	}
+	return data, err
+}
+
+func (s *RedisStore) SaveTable(chatID int64, data []byte) error {
+	err := s.Client.Set(tableKey(chatID), data, 0).Err()
+	if err != nil {
+		return err
+	}
+	return s.Client.SAdd(tablesKey, chatID).Err()
+}
+
+func (s *RedisStore) DeleteTable(chatID int64) error {
+	err := s.Client.Del(tableKey(chatID)).Err()
+	if err != nil {
+		return err
+	}
+	return s.Client.SRem(tablesKey, chatID).Err()
+}
+
+func (s *RedisStore) Tables() ([]int64, error) {
+	return s.members(tablesKey)
+}
+
+// members reads a set of chat IDs.
+func (s *RedisStore) members(key string) ([]int64, error) {
+	members, err := s.Client.SMembers(key).Result()
+	if err != nil {
+		return nil, err
+	}
+	chatIDs := make([]int64, 0, len(members))
+	for _, member := range members {
+		chatID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+	return chatIDs, nil
+}
+
+func (s *RedisStore) NextHandID() (int64, error) {
+	return s.Client.Incr(handIDKey).Result()
+}
+
+func (s *RedisStore) SaveHand(handID int64, chatID int64, userIDs []int,
+	data []byte) error {
+	_, err := s.Client.Pipelined(func(pipe *redis.Pipeline) error {
+		pipe.Set(handKey(handID), data, 0)
+		lists := []string{tableHandsKey(chatID)}
+		for _, userID := range userIDs {
+			lists = append(lists, userHandsKey(userID))
+		}
+		for _, list := range lists {
+			pipe.LPush(list, handID)
+			pipe.LTrim(list, 0, maxHands-1)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) LoadHand(handID int64) ([]byte, error) {
+	data, err := s.Client.Get(handKey(handID)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNoHand
+	}
+	return data, err
+}
+
+func (s *RedisStore) UserHands(userID int, n int) ([]int64, error) {
+	return s.hands(userHandsKey(userID), n)
+}
+
+func (s *RedisStore) TableHands(chatID int64, n int) ([]int64, error) {
+	return s.hands(tableHandsKey(chatID), n)
+}
+
+// hands reads the first n hand IDs of a list.
+func (s *RedisStore) hands(key string, n int) ([]int64, error) {
+	members, err := s.Client.LRange(key, 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	handIDs := make([]int64, 0, len(members))
+	for _, member := range members {
+		handID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		handIDs = append(handIDs, handID)
+	}
+	return handIDs, nil
+}
+
+func (s *RedisStore) AddStats(userID int, chatID int64,
+	stats map[string]int64) error {
+	_, err := s.Client.Pipelined(func(pipe *redis.Pipeline) error {
+		for field, value := range stats {
+			pipe.HIncrBy(statsKey(userID, chatID), field, value)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) Stats(userID int, chatID int64) (map[string]int64,
+	error) {
+	fields, err := s.Client.HGetAll(statsKey(userID, chatID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	stats := map[string]int64{}
+	for field, value := range fields {
+		stats[field], err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+func (s *RedisStore) IncrRank(board string, chatID int64, userID int,
+	delta float64) (float64, error) {
+	if chatID != 0 {
+		err := s.Client.SAdd(rankedKey, chatID).Err()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return s.Client.ZIncrBy(rankKey(board, chatID), delta,
+		strconv.Itoa(userID)).Result()
+}
+
+func (s *RedisStore) SetRank(board string, chatID int64, userID int,
+	score float64) error {
+	if chatID != 0 {
+		err := s.Client.SAdd(rankedKey, chatID).Err()
+		if err != nil {
+			return err
+		}
+	}
+	return s.Client.ZAdd(rankKey(board, chatID), redis.Z{
+		Score:  score,
+		Member: strconv.Itoa(userID),
+	}).Err()
+}
+
+func (s *RedisStore) RemoveRank(board string, chatID int64, userID int) error {
+	return s.Client.ZRem(rankKey(board, chatID), strconv.Itoa(userID)).Err()
+}
+
+func (s *RedisStore) Ranks(board string, chatID int64, n int) ([]*Rank,
+	error) {
+	members, err := s.Client.ZRevRangeWithScores(rankKey(board, chatID), 0,
+		int64(n-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	ranks := make([]*Rank, 0, len(members))
+	for _, member := range members {
+		userID, err := strconv.Atoi(member.Member.(string))
+		if err != nil {
+			return nil, err
+		}
+		ranks = append(ranks, &Rank{UserID: userID, Score: member.Score})
+	}
+	return ranks, nil
+}
+
+func (s *RedisStore) RankedChats() ([]int64, error) {
+	return s.members(rankedKey)
+}
+
+func (s *RedisStore) Season() (int64, time.Time, error) {
+	fields, err := s.Client.HGetAll(seasonKey).Result()
+	if err != nil || len(fields) == 0 {
+		return 0, time.Time{}, err
+	}
+	season, err := strconv.ParseInt(fields["season"], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	start, err := strconv.ParseInt(fields["start"], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return season, time.Unix(start, 0), nil
+}
+
+func (s *RedisStore) StartSeason(season int64, start time.Time) error {
+	if season > 0 {
+		err := s.archiveSeason(season)
+		if err != nil {
+			return err
+		}
+	}
+	return s.Client.HMSet(seasonKey, map[string]string{
+		"season": strconv.FormatInt(season+1, 10),
+		"start":  strconv.FormatInt(start.Unix(), 10),
+	}).Err()
+}
+
+// Move every leaderboard under the season.
+func (s *RedisStore) archiveSeason(season int64) error {
+	chatIDs, err := s.RankedChats()
+	if err != nil {
+		return err
+	}
+	chatIDs = append(chatIDs, 0)
+	for _, chatID := range chatIDs {
+		for _, board := range Leaderboards {
+			err := s.Client.Rename(rankKey(board, chatID),
+				seasonRankKey(season, board, chatID)).Err()
+			// The leaderboard may not exist.
+			if err != nil && !strings.Contains(err.Error(), "no such key") {
+				return err
+			}
+		}
+	}
+	return s.Client.Del(rankedKey).Err()
+}
+
+func (s *RedisStore) Ping() error {
+	return s.Client.Ping().Err()
+}
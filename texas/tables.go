@@ -0,0 +1,161 @@
+package texas
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// Table runs the requests of a chat one by one in its own goroutine, which
+// owns the game of the chat.
+type Table struct {
+	ChatID   int64
+	Game     *Texas
+	requests chan *tableRequest
+	running  bool
+}
+
+type tableRequest struct {
+	Run  func()
+	Done chan struct{}
+}
+
+// Requests queued for a table at most.
+const tableQueue = 64
+
+var (
+	ErrTableBusy    = errors.New("Table is busy.")
+	ErrTableTimeout = errors.New("Table timed out.")
+	ErrNoGame       = errors.New("Game is not ready.")
+)
+
+var (
+	tables      = map[int64]*Table{}
+	tablesMutex sync.Mutex
+)
+
+// Find the table of a chat, or create it. Must hold tablesMutex.
+func getTable(chatID int64) *Table {
+	table := tables[chatID]
+	if table == nil {
+		table = &Table{
+			ChatID:   chatID,
+			requests: make(chan *tableRequest, tableQueue),
+		}
+		tables[chatID] = table
+	}
+	return table
+}
+
+// Get the game of a chat, nil if there is not one.
+func getGame(chatID int64) *Texas {
+	tablesMutex.Lock()
+	defer tablesMutex.Unlock()
+	if table := tables[chatID]; table != nil {
+		return table.Game
+	}
+	return nil
+}
+
+// Set the game of a chat, nil when the game ends.
+func setGame(chatID int64, game *Texas) {
+	tablesMutex.Lock()
+	defer tablesMutex.Unlock()
+	getTable(chatID).Game = game
+}
+
+// Queue f to run on the table of the chat, starting the table if it is idle.
+// The returned channel is closed once f returns.
+func sendTable(chatID int64, f func()) (chan struct{}, error) {
+	tablesMutex.Lock()
+	defer tablesMutex.Unlock()
+	return queueTable(getTable(chatID), f)
+}
+
+// Queue f like sendTable if the chat has a game, so requests from outside
+// the chat do not start tables of any chat.
+func sendGame(chatID int64, f func()) (chan struct{}, error) {
+	tablesMutex.Lock()
+	defer tablesMutex.Unlock()
+	table := tables[chatID]
+	if table == nil || table.Game == nil {
+		return nil, ErrNoGame
+	}
+	return queueTable(table, f)
+}
+
+// Queue f to run on the table. Must hold tablesMutex.
+func queueTable(table *Table, f func()) (chan struct{}, error) {
+	request := &tableRequest{
+		Run:  f,
+		Done: make(chan struct{}),
+	}
+	select {
+	case table.requests <- request:
+	default:
+		return nil, ErrTableBusy
+	}
+	if !table.running {
+		table.running = true
+		go table.run()
+	}
+	return request.Done, nil
+}
+
+// Wait for a request sent to a table to finish.
+func waitTable(done chan struct{}) error {
+	select {
+	case <-done:
+		return nil
+	case <-time.After(config.Bot().TableTimeout):
+		return ErrTableTimeout
+	}
+}
+
+// Run f on the table of the chat in the background and log if it fails to
+// finish in time.
+func runOnTable(chatID int64, f func()) error {
+	done, err := sendTable(chatID, f)
+	if err != nil {
+		return err
+	}
+	go func() {
+		err := waitTable(done)
+		if err != nil {
+			log.Println("Error:", err, "< runOnTable", chatID)
+		}
+	}()
+	return nil
+}
+
+func (t *Table) run() {
+	for {
+		select {
+		case request := <-t.requests:
+			request.Run()
+			close(request.Done)
+		case <-time.After(config.Bot().TableIdle):
+			if t.stop() {
+				return
+			}
+		}
+	}
+}
+
+// Stop the table if nothing is queued. Tables without a game are forgotten,
+// the others start again on the next request.
+func (t *Table) stop() bool {
+	tablesMutex.Lock()
+	defer tablesMutex.Unlock()
+	if len(t.requests) > 0 {
+		return false
+	}
+	t.running = false
+	if t.Game == nil {
+		delete(tables, t.ChatID)
+	}
+	return true
+}
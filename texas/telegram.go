@@ -0,0 +1,128 @@
+package texas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+const telegramAPI = "https://api.telegram.org/bot"
+
+// Calls the bot library does not make give up on a stuck Telegram after the
+// timeout, so they do not hold a table forever.
+var telegramClient = &http.Client{Timeout: 30 * time.Second}
+
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// Call a method of the Bot API the bot library does not have.
+func callTelegram(method string, params interface{}) (json.RawMessage,
+	error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := telegramClient.Post(telegramAPI+config.Bot().Token+"/"+method,
+		"application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return readTelegramResponse(method, resp)
+}
+
+// Whether an edit failed because the message would not change.
+func isNotModified(err error) bool {
+	return strings.Contains(err.Error(), "message is not modified")
+}
+
+// Whether an edit failed because the message was deleted or is too old to
+// edit.
+func isMessageGone(err error) bool {
+	text := err.Error()
+	return strings.Contains(text, "message to edit not found") ||
+		strings.Contains(text, "message can't be edited") ||
+		strings.Contains(text, "MESSAGE_ID_INVALID")
+}
+
+func readTelegramResponse(method string, resp *http.Response) (
+	json.RawMessage, error) {
+	defer resp.Body.Close()
+	result := &telegramResponse{}
+	err := json.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return nil, err
+	}
+	if !result.OK {
+		return nil, errors.New(method + ": " + result.Description)
+	}
+	return result.Result, nil
+}
+
+// Upload a file to a chat. The bot library only sends files by file ID, so
+// the request is built here.
+func uploadFile(method string, field string, chatID int64, filename string,
+	data []byte, params map[string]string) (json.RawMessage, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("chat_id", strconv.FormatInt(chatID, 10))
+	for key, value := range params {
+		writer.WriteField(key, value)
+	}
+	part, err := writer.CreateFormFile(field, filename)
+	if err != nil {
+		return nil, err
+	}
+	part.Write(data)
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := telegramClient.Post(telegramAPI+config.Bot().Token+"/"+method,
+		writer.FormDataContentType(), body)
+	if err != nil {
+		return nil, err
+	}
+	return readTelegramResponse(method, resp)
+}
+
+// Send a file as a document.
+func sendDocument(chatID int64, filename string, data []byte,
+	caption string) error {
+	_, err := uploadFile("sendDocument", "document", chatID, filename, data,
+		map[string]string{"caption": caption})
+	return err
+}
+
+// Send an image as a photo.
+func sendPhoto(chatID int64, filename string, data []byte,
+	caption string) error {
+	_, err := uploadFile("sendPhoto", "photo", chatID, filename, data,
+		map[string]string{"caption": caption})
+	return err
+}
+
+// Ask Telegram to send updates to url with the secret token.
+func setWebhook(url string, secret string) error {
+	_, err := callTelegram("setWebhook", map[string]interface{}{
+		"url":             url,
+		"secret_token":    secret,
+		"allowed_updates": []string{"message", "callback_query"},
+	})
+	return err
+}
+
+// Stop sending updates to the webhook, so long polling works again.
+func deleteWebhook() error {
+	_, err := callTelegram("deleteWebhook", map[string]interface{}{})
+	return err
+}
@@ -1,4 +1,4 @@
-package main
+package texas
 
 import (
 	"errors"
@@ -10,7 +10,6 @@ import (
 
 	. "github.com/magicae/telegram-bot"
 	"github.com/magicae/texas-holdem-bot/config"
-	"gopkg.in/redis.v5"
 )
 
 // Suits of poker
@@ -45,12 +44,24 @@ var StageNames []string = []string{"Init", "Compulsory Bets", "Preflop", "Flop",
 
 type (
 	Texas struct {
-		Bot     *Bot
+		Bot     *Bot `json:"-"`
 		ChatID  int64
 		Players [10]*TexasPlayer
 		Dealer  int
 		MaxChip int64
-		Round   *Round
+		// Blinds of the table.
+		SmallBlind int64
+		BigBlind   int64
+		Round      *Round
+		// LastHandID is the ID of the last recorded hand.
+		LastHandID int64
+		// Owner is the user who started the game by /new.
+		Owner int
+		// Paused tables wait for /resume before anyone acts.
+		Paused bool
+		// Web clients find public tables and can join them. Only the
+		// players see the other tables.
+		Public bool
 	}
 
 	TexasPlayer struct {
@@ -86,6 +97,12 @@ type (
 		ActorIndex            int
 		LastRaiser            int
 		IgnoreLastRaiserCheck bool
+		History               *HandRecord
+		// StatusMessageID is the table message edited as the hand moves on.
+		StatusMessageID int
+		// LeftUsers are the users who left their seats during the hand.
+		// Their bets stay in the pot.
+		LeftUsers [10]int
 	}
 
 	PlayerHand struct {
@@ -100,14 +117,21 @@ type (
 // Create a new game for everyone.
 func NewTexas(e *Bot, chatID int64, maxChip int64) *Texas {
 	texas := &Texas{
-		Bot:     e,
-		ChatID:  chatID,
-		Dealer:  0,
-		MaxChip: maxChip,
+		Bot:        e,
+		ChatID:     chatID,
+		Dealer:     0,
+		MaxChip:    maxChip,
+		SmallBlind: config.Bot().SmallBlind,
+		BigBlind:   config.Bot().BigBlind,
 	}
 	return texas
 }
 
+// Language of the group.
+func (t *Texas) Lang() string {
+	return getLanguage(t.ChatID)
+}
+
 // Create a new dealer for a new card pack.
 func NewTexasDealer() *TexasDealer {
 	dealer := &TexasDealer{
@@ -142,10 +166,9 @@ func (d *TexasDealer) Deal() *PokerCard {
 
 // Add a user into the game. Returns the chips bought.
 func (t *Texas) AddUser(user *User) (int64, error) {
-	chatKey := "texas:user:" + strconv.Itoa(user.ID) + ":chat"
-	_, err := redisClient.Get(chatKey).Result()
-	if err == redis.Nil {
-		return 0, errors.New("You need /start in the private chat at first!")
+	_, err := store.PrivateChat(user.ID)
+	if err != nil {
+		return 0, err
 	}
 	for i := 0; i < 10; i++ {
 		if t.Players[i] != nil && t.Players[i].UserID == user.ID {
@@ -155,22 +178,29 @@ func (t *Texas) AddUser(user *User) (int64, error) {
 	for i := 0; i < 10; i++ {
 		// Find an empty seat.
 		if t.Players[i] == nil {
-			// Get the user's money.
-			moneyKey := "texas:user:" + strconv.Itoa(user.ID) + ":money"
-			chip, err := redisClient.Get(moneyKey).Int64()
-			if err != nil && err != redis.Nil {
+			balance, err := store.Balance(user.ID)
+			if err != nil {
 				return 0, err
 			}
-			if err == redis.Nil || chip <= 0 {
+			if balance < config.Bot().MinBuyIn {
+				return 0, errors.New("You are too poor to join game.")
+			}
+			// Move the user's money into the escrow of this table.
+			buy, err := store.BuyIn(user.ID, t.ChatID, t.MaxChip)
+			if err == ErrNoMoney {
 				return 0, errors.New("You are too poor to join game.")
 			}
-			// Determine how many chips he can buy.
-			buy := min(chip, t.MaxChip)
-			// Decrease the user's money.
-			err = redisClient.DecrBy(moneyKey, buy).Err()
 			if err != nil {
 				return 0, err
 			}
+			err = store.SetUsername(user.ID, user.Username)
+			if err != nil {
+				log.Println("Error: ", err, "< AddUser")
+			}
+			err = store.SetDisplayName(user.ID, getUserDisplayName(user))
+			if err != nil {
+				log.Println("Error: ", err, "< AddUser")
+			}
 			// Add to the game.
 			t.Players[i] = &TexasPlayer{
 				UserID:      user.ID,
@@ -191,11 +221,13 @@ func (t *Texas) RemoveUser(user *User) (int64, error) {
 		if t.Players[i] != nil && t.Players[i].UserID == user.ID {
 			// Return money to the user.
 			get := t.Players[i].Chip
-			moneyKey := "texas:user:" + strconv.Itoa(user.ID) + ":money"
-			err := redisClient.IncrBy(moneyKey, get).Err()
+			err := store.CashOut(user.ID, t.ChatID, get, t.LastHandID)
 			if err != nil {
 				return 0, err
 			}
+			if t.Round != nil && t.Round.Stage < End {
+				t.Round.LeftUsers[i] = user.ID
+			}
 			t.Players[i] = nil
 			return get, nil
 		}
@@ -203,6 +235,19 @@ func (t *Texas) RemoveUser(user *User) (int64, error) {
 	return 0, errors.New("You are currently not in this game.")
 }
 
+// Record the chips of every player in the escrow of this table.
+func (t *Texas) SaveEscrow() {
+	for i := 0; i < 10; i++ {
+		if t.Players[i] != nil {
+			err := store.SetEscrow(t.Players[i].UserID, t.ChatID,
+				t.Players[i].Chip)
+			if err != nil {
+				log.Println("Error: ", err, "< SaveEscrow")
+			}
+		}
+	}
+}
+
 // Count players.
 func (t *Texas) CountUser() int {
 	count := 0
@@ -241,6 +286,9 @@ func (t *Texas) StartRound() error {
 	if t.Round != nil && t.Round.Stage != End {
 		return errors.New("This round is still taking.")
 	}
+	if t.Paused {
+		return ErrTablePaused
+	}
 	// TODO: add this
 	if t.CountUser() < 2 {
 		return errors.New("Not enough players to start a new round.")
@@ -270,31 +318,51 @@ func (t *Texas) StartRound() error {
 			t.Round.UserState[i] = Out
 		}
 	}
+	t.StartHistory()
 	return nil
 }
 
+// Cancel the round and return everyone's bets of it.
+func (t *Texas) CancelRound() {
+	if t.Round == nil || t.Round.Stage == End {
+		return
+	}
+	for i := 0; i < 10; i++ {
+		bets := t.Round.TotalBets[i]
+		if userID := t.Round.LeftUsers[i]; userID != 0 {
+			// The user is not at the table any more, so the bets go back
+			// to the wallet.
+			if bets > 0 {
+				_, err := store.Credit(userID, bets, LedgerEntry{
+					Reason: LedgerRefund,
+					ChatID: t.ChatID,
+				})
+				if err != nil {
+					log.Println("Error:", err, "< CancelRound")
+				}
+			}
+		} else if t.Players[i] != nil {
+			t.Players[i].Chip += bets
+		}
+	}
+	t.Round = nil
+}
+
 func (t *Texas) SendMaxHand(stage int) {
 	// Notify max rank
 	for i := 0; i < 10; i++ {
 		if t.Round.UserState[i] == InGame {
-			chatKey := "texas:user:" + strconv.Itoa(t.Players[i].UserID) +
-				":chat"
-			chatID, err := redisClient.Get(chatKey).Int64()
+			chatID, err := store.PrivateChat(t.Players[i].UserID)
 			if err != nil {
 				log.Println("Error: ", err, "< SendMaxHand")
 				continue
 			}
 			t.Round.TopCards[i] = getTopCards(t.Round.CommunityCards,
 				t.Round.PlayerCards[i])
-			_, err = t.Bot.SendMessage(&SendMessageRequest{
-				ChatID: chatID,
-				Text: "[" + StageNames[stage] + "] You got " +
-					PokerHands[t.Round.TopCards[i].GetRank()] + "!",
-			})
-			if err != nil {
-				log.Println("Error: ", err, "< SendMaxHand")
-				continue
-			}
+			lang := getLanguage(chatID)
+			t.SendHand(chatID, i, trf(lang, "[%s] You got %s!",
+				tr(lang, StageNames[stage]),
+				tr(lang, PokerHands[t.Round.TopCards[i].GetRank()])))
 		}
 	}
 }
@@ -309,32 +377,17 @@ func (t *Texas) MoveOn() error {
 		// Deal cards to every one.
 		for i := 0; i < 10; i++ {
 			if t.Round.UserState[i] == InGame {
-				chatKey := "texas:user:" + strconv.Itoa(t.Players[i].UserID) +
-					":chat"
-				chatID, err := redisClient.Get(chatKey).Int64()
-				if err != nil {
-					return err
-				}
-				_, err = t.Bot.SendMessage(&SendMessageRequest{
-					ChatID: chatID,
-					Text:   "New round starts! Dealing for you. Good luck!",
-				})
+				chatID, err := store.PrivateChat(t.Players[i].UserID)
 				if err != nil {
 					return err
 				}
 				t.Round.Earn[i] = 0
 				t.Round.CardDealer.Deal() // Dealer skips a card.
 				t.Round.PlayerCards[i][0] = t.Round.CardDealer.Deal()
-				go t.Bot.SendSticker(&SendStickerRequest{
-					ChatID:  chatID,
-					Sticker: getPokerSticker(t.Round.PlayerCards[i][0]),
-				})
 				t.Round.CardDealer.Deal() // Dealer skips a card.
 				t.Round.PlayerCards[i][1] = t.Round.CardDealer.Deal()
-				go t.Bot.SendSticker(&SendStickerRequest{
-					ChatID:  chatID,
-					Sticker: getPokerSticker(t.Round.PlayerCards[i][1]),
-				})
+				t.SendHand(chatID, i, tr(getLanguage(chatID),
+					"New round starts! Dealing for you. Good luck!"))
 			}
 		}
 		t.Round.Stage = CompulsoryBets
@@ -344,9 +397,11 @@ func (t *Texas) MoveOn() error {
 			t.Round.StageBets[i] = 0
 		}
 		smallBlind := t.Round.NextValidIndex(t.Round.Dealer)
-		t.MakeBet(smallBlind, 50)
+		t.MakeBet(smallBlind, t.SmallBlind)
+		t.RecordAction(smallBlind, ActionSmallBlind, t.Round.StageBets[smallBlind])
 		bigBlind := t.Round.NextValidIndex(smallBlind)
-		t.MakeBet(bigBlind, 100)
+		t.MakeBet(bigBlind, t.BigBlind)
+		t.RecordAction(bigBlind, ActionBigBlind, t.Round.StageBets[bigBlind])
 		t.Round.ActorIndex = t.Round.NextValidIndex(bigBlind)
 		t.Round.LastRaiser = t.Round.NextValidIndex(bigBlind)
 		t.Round.Stage = Preflop
@@ -359,27 +414,13 @@ func (t *Texas) MoveOn() error {
 		}
 		t.Round.CardDealer.Deal() // Dealer skips a card.
 		t.Round.CommunityCards[0] = t.Round.CardDealer.Deal()
-		// TODO: error handler
-		t.Bot.SendSticker(&SendStickerRequest{
-			ChatID:  t.ChatID,
-			Sticker: getPokerSticker(t.Round.CommunityCards[0]),
-		})
 		t.Round.CardDealer.Deal() // Dealer skips a card.
 		t.Round.CommunityCards[1] = t.Round.CardDealer.Deal()
-		// TODO: error handler
-		t.Bot.SendSticker(&SendStickerRequest{
-			ChatID:  t.ChatID,
-			Sticker: getPokerSticker(t.Round.CommunityCards[1]),
-		})
 		t.Round.CardDealer.Deal() // Dealer skips a card.
 		t.Round.CommunityCards[2] = t.Round.CardDealer.Deal()
-		// TODO: error handler
-		t.Bot.SendSticker(&SendStickerRequest{
-			ChatID:  t.ChatID,
-			Sticker: getPokerSticker(t.Round.CommunityCards[2]),
-		})
 		t.Round.LastRaiser = t.Round.NextValidIndex(t.Round.Dealer)
 		t.Round.ActorIndex = t.Round.Dealer
+		t.SendBoard(t.Round.CommunityCards[:3]...)
 		t.SendMaxHand(t.Round.Stage)
 		t.Round.IgnoreLastRaiserCheck = true
 		return t.NextPlayer()
@@ -389,13 +430,9 @@ func (t *Texas) MoveOn() error {
 		}
 		t.Round.CardDealer.Deal() // Dealer skips a card.
 		t.Round.CommunityCards[3] = t.Round.CardDealer.Deal()
-		// TODO: error handler
-		t.Bot.SendSticker(&SendStickerRequest{
-			ChatID:  t.ChatID,
-			Sticker: getPokerSticker(t.Round.CommunityCards[3]),
-		})
 		t.Round.LastRaiser = t.Round.NextValidIndex(t.Round.Dealer)
 		t.Round.ActorIndex = t.Round.Dealer
+		t.SendBoard(t.Round.CommunityCards[3])
 		t.SendMaxHand(t.Round.Stage)
 		t.Round.IgnoreLastRaiserCheck = true
 		return t.NextPlayer()
@@ -405,13 +442,9 @@ func (t *Texas) MoveOn() error {
 		}
 		t.Round.CardDealer.Deal() // Dealer skips a card.
 		t.Round.CommunityCards[4] = t.Round.CardDealer.Deal()
-		// TODO: error handler
-		t.Bot.SendSticker(&SendStickerRequest{
-			ChatID:  t.ChatID,
-			Sticker: getPokerSticker(t.Round.CommunityCards[4]),
-		})
 		t.Round.LastRaiser = t.Round.NextValidIndex(t.Round.Dealer)
 		t.Round.ActorIndex = t.Round.Dealer
+		t.SendBoard(t.Round.CommunityCards[4])
 		t.SendMaxHand(t.Round.Stage)
 		t.Round.IgnoreLastRaiserCheck = true
 		return t.NextPlayer()
@@ -552,6 +585,7 @@ func (t *Texas) Fold(userID int) error {
 	_, index := t.getMaxAndCurrentUserIndex(userID)
 	if index >= 0 {
 		t.Round.UserState[index] = Fold
+		t.RecordAction(index, ActionFold, 0)
 		if index == t.Round.LastRaiser {
 			// Make raiser to next one
 			t.Round.LastRaiser = t.Round.NextValidIndex(t.Round.LastRaiser)
@@ -577,7 +611,9 @@ func (t *Texas) Call(userID int) error {
 		if max == t.Round.StageBets[index] {
 			return errors.New("You can only /check, /raise or /fold.")
 		}
+		bet := t.Round.StageBets[index]
 		t.MakeBet(index, (max - t.Round.StageBets[index]))
+		t.RecordAction(index, ActionCall, t.Round.StageBets[index]-bet)
 		return t.NextPlayer()
 	}
 	return nil
@@ -587,6 +623,7 @@ func (t *Texas) Check(userID int) error {
 	max, index := t.getMaxAndCurrentUserIndex(userID)
 	if index >= 0 {
 		if max <= t.Round.StageBets[index] {
+			t.RecordAction(index, ActionCheck, 0)
 			return t.NextPlayer()
 		} else {
 			return errors.New("You can only /call, /raise or /fold.")
@@ -598,14 +635,19 @@ func (t *Texas) Check(userID int) error {
 func (t *Texas) Raise(userID int, amount int64) error {
 	max, index := t.getMaxAndCurrentUserIndex(userID)
 	if index >= 0 {
-		if amount < 100 {
-			return errors.New("Cannot /raise less than 100.")
+		if amount < t.BigBlind {
+			return errors.New("Cannot /raise less than the big blind.")
 		}
 		delta := amount + (max - t.Round.StageBets[index])
 		if t.Players[index].Chip <= delta {
 			return errors.New("No enough chips for raising. /allin?")
 		} else {
 			t.MakeBet(index, delta)
+			if max > 0 {
+				t.RecordAction(index, ActionRaise, delta)
+			} else {
+				t.RecordAction(index, ActionBet, delta)
+			}
 			t.Round.LastRaiser = index
 			return t.NextPlayer()
 		}
@@ -613,6 +655,67 @@ func (t *Texas) Raise(userID int, amount int64) error {
 	return nil
 }
 
+// Raise to a total bet of this stage.
+func (t *Texas) RaiseTo(userID int, to int64) error {
+	return t.Raise(userID, to-t.getMaxBet())
+}
+
+// RaiseOption is a total bet the actor can raise to.
+type RaiseOption struct {
+	Name string
+	To   int64
+}
+
+// Sizes relative to the pot after calling, in quarters.
+var potRaises = []struct {
+	Name     string
+	Quarters int64
+}{
+	{"½ pot", 2},
+	{"¾ pot", 3},
+	{"Pot", 4},
+	{"2× pot", 8},
+}
+
+// Total bet of raising the pot after calling times quarters/4, rounded down
+// to the small blind.
+func (t *Texas) PotRaiseTo(quarters int64) int64 {
+	max := t.getMaxBet()
+	call := max - t.Round.StageBets[t.Round.ActorIndex]
+	return (max + (t.Round.Pot+call)*quarters/4) / t.SmallBlind * t.SmallBlind
+}
+
+// Legal sizes for the actor to raise to, from the smallest. All-in is not
+// one of them.
+func (t *Texas) RaiseOptions() []*RaiseOption {
+	actor := t.Round.ActorIndex
+	max := t.getMaxBet()
+	// Raise at least the last raise of this stage, or the big blind.
+	var last int64 = 0
+	for i := 0; i < 10; i++ {
+		if t.Round.StageBets[i] < max && t.Round.StageBets[i] > last {
+			last = t.Round.StageBets[i]
+		}
+	}
+	minTo := max + t.BigBlind
+	if max-last > t.BigBlind {
+		minTo = max + max - last
+	}
+	allIn := t.Round.StageBets[actor] + t.Players[actor].Chip
+	options := make([]*RaiseOption, 0)
+	if minTo >= allIn {
+		return options
+	}
+	options = append(options, &RaiseOption{"Min", minTo})
+	for _, raise := range potRaises {
+		to := t.PotRaiseTo(raise.Quarters)
+		if to > options[len(options)-1].To && to < allIn {
+			options = append(options, &RaiseOption{raise.Name, to})
+		}
+	}
+	return options
+}
+
 func (t *Texas) GetOut(userID int) error {
 	for i := 0; i < 10; i++ {
 		if t.Round != nil && t.Players[i] != nil && t.Players[i].UserID == userID {
@@ -627,9 +730,17 @@ func (t *Texas) AllIn(userID int) error {
 	max, index := t.getMaxAndCurrentUserIndex(userID)
 	if index >= 0 {
 		all := t.Players[index].Chip + t.Round.StageBets[index]
+		bet := t.Players[index].Chip
 		t.MakeBet(index, t.Players[index].Chip)
 		if all > max {
 			t.Round.LastRaiser = index
+			if max > 0 {
+				t.RecordAction(index, ActionRaise, bet)
+			} else {
+				t.RecordAction(index, ActionBet, bet)
+			}
+		} else {
+			t.RecordAction(index, ActionCall, bet)
 		}
 		return t.NextPlayer()
 	}
@@ -637,71 +748,115 @@ func (t *Texas) AllIn(userID int) error {
 }
 
 func (t *Texas) Showdown() error {
-	text := "= SHOWDOWN =\nCommunity Cards:"
-	for i := 0; i < 5; i++ {
-		text += " " + getPokerText(t.Round.CommunityCards[i])
-	}
-	text += "\n"
+	text := t.ShowdownText()
+	t.getResultForShowdown()
+	_, err := t.Bot.SendMessage(&SendMessageRequest{
+		ChatID: t.ChatID,
+		Text:   text,
+	})
+	return err
+}
+
+// Text of everyone's cards at showdown.
+func (t *Texas) ShowdownText() string {
+	lang := t.Lang()
+	style := getCardStyle(t.ChatID)
+	text := tr(lang, "= SHOWDOWN =") + "\n" + tr(lang, "Community cards:") +
+		" " + getCardsText(style, lang, t.Round.CommunityCards[:]...) + "\n"
 	count := 0
 	for i := 0; i < 10; i++ {
 		if t.Round.UserState[i] == InGame {
 			count += 1
-			text += fmt.Sprintf("[%d] %s(%d) - %s %s \\%s/\n", count,
+			text += fmt.Sprintf("[%d] %s(%d) - %s \\%s/\n", count,
 				t.Players[i].DisplayName,
 				t.Players[i].Chip,
-				getPokerText(t.Round.PlayerCards[i][0]),
-				getPokerText(t.Round.PlayerCards[i][1]),
-				PokerHands[t.Round.TopCards[i].GetRank()])
+				getCardsText(style, lang, t.Round.PlayerCards[i][:]...),
+				tr(lang, PokerHands[t.Round.TopCards[i].GetRank()]))
 		} else if t.Round.UserState[i] == Fold {
 			count += 1
-			text += fmt.Sprintf("[%d] %s(%d) - FOLD\n", count,
+			text += fmt.Sprintf("[%d] %s(%d) - %s\n", count,
 				t.Players[i].DisplayName,
-				t.Players[i].Chip)
+				t.Players[i].Chip, tr(lang, "FOLD"))
 		}
 	}
-	t.getResultForShowdown()
-	_, err := t.Bot.SendMessage(&SendMessageRequest{
-		ChatID: t.ChatID,
-		Text:   text,
-	})
-	return err
+	return text
 }
 
-func (t *Texas) ShowStatus() error {
-	text := "- " + StageNames[t.Round.Stage] + " - Pot: " +
-		strconv.FormatInt(t.Round.Pot, 10) + "\nCommunity cards:"
-	for i := 0; i < 5; i++ {
-		if t.Round.CommunityCards[i] != nil {
-			text += " " + getPokerText(t.Round.CommunityCards[i])
-		} else {
-			text += " ？"
+// Text of the stage, the pot and community cards.
+func (t *Texas) StatusHeader() string {
+	lang := t.Lang()
+	text := trf(lang, "- %s - Pot: %d", tr(lang, StageNames[t.Round.Stage]),
+		t.Round.Pot) + "\n" + tr(lang, "Community cards:") + " " +
+		getCardsText(getCardStyle(t.ChatID), lang,
+			t.Round.CommunityCards[:]...) + "\n"
+	return text
+}
+
+// Text of bets and chips of everyone, pointing at the actor.
+func (t *Texas) StatusPlayers() string {
+	lang := t.Lang()
+	text := ""
+	count := 0
+	for i := 0; i < 10; i++ {
+		if t.Round.UserState[i] != Out {
+			count++
+			if i == t.Round.ActorIndex {
+				text += "-> "
+			}
+			text += fmt.Sprintf("[%d] %s", count, t.Players[i].DisplayName)
+			if t.Round.UserState[i] == Fold {
+				text += " " + tr(lang, "FOLD")
+			} else {
+				if t.Round.StageBets[i] > 0 {
+					text += " +"
+				} else {
+					text += " "
+				}
+				text += strconv.FormatInt(t.Round.StageBets[i], 10)
+				text += " / $" + strconv.FormatInt(t.Players[i].Chip, 10)
+				if t.Players[i].Chip <= 0 {
+					text += " " + tr(lang, "*ALL IN*")
+				}
+			}
+			if i == t.Round.Dealer {
+				text += " " + tr(lang, "(Dealer)")
+			}
+			text += "\n"
 		}
 	}
-	text += "\n"
-	buttons := make([]*KeyboardButton, 0)
-	selective := true
+	return text
+}
+
+func (t *Texas) ShowStatus() error {
+	lang := t.Lang()
+	text := t.StatusHeader()
+	var buttons [][]*InlineKeyboardButton
 	if t.Round.Stage == End {
+		t.SaveHistory()
 		count := 0
 		for i := 0; i < 10; i++ {
 			if t.Round.UserState[i] != Out {
 				count++
 				text += fmt.Sprintf("[%d] %s", count, t.Players[i].DisplayName)
 				if t.Round.UserState[i] == Fold {
-					text += " FOLD"
+					text += " " + tr(lang, "FOLD")
 				} else if t.Round.Earn[i]-t.Round.TotalBets[i] >= 0 {
-					text += " WIN +" + strconv.FormatInt(t.Round.Earn[i]-t.Round.TotalBets[i], 10)
+					text += " " + trf(lang, "WIN +%d",
+						t.Round.Earn[i]-t.Round.TotalBets[i])
 					t.Players[i].Chip += t.Round.Earn[i]
 				} else {
-					text += " LOSE"
+					text += " " + tr(lang, "LOSE")
 					t.Players[i].Chip += t.Round.Earn[i]
 				}
-				text += " -> " + strconv.FormatInt(t.Players[i].Chip, 10) + " chips."
+				text += " " + trn(lang, t.Players[i].Chip, "-> %d chip.",
+					"-> %d chips.", t.Players[i].Chip)
 				if t.Players[i].Chip <= 0 {
 					defer t.Bot.SendMessage(&SendMessageRequest{
 						ChatID: t.ChatID,
-						Text:   fmt.Sprintf("%s (@%s) gets out of game!", t.Players[i].DisplayName, t.Players[i].Username),
+						Text: trf(lang, "%s (@%s) gets out of game!",
+							t.Players[i].DisplayName, t.Players[i].Username),
 						ReplyMarkup: &ReplyKeyboardMarkup{
-							Keyboard:        [][]*KeyboardButton{config.Bot.OutButtons},
+							Keyboard:        [][]*KeyboardButton{config.Bot().OutButtons},
 							ResizeKeyboard:  true,
 							OneTimeKeyboard: true,
 							Selective:       true,
@@ -714,87 +869,101 @@ func (t *Texas) ShowStatus() error {
 				text += "\n"
 			}
 		}
-		buttons = config.Bot.InGameButtons
-		selective = false
+		t.SaveEscrow()
+		buttons = [][]*InlineKeyboardButton{
+			[]*InlineKeyboardButton{
+				&InlineKeyboardButton{
+					Text:         tr(lang, "Next hand"),
+					CallbackData: "startgame",
+				},
+				&InlineKeyboardButton{
+					Text:         tr(lang, "Leave"),
+					CallbackData: "leave",
+				},
+			},
+		}
 	} else {
-		count := 0
-		var max int64 = 0
 		actor := t.Round.ActorIndex
-		for i := 0; i < 10; i++ {
-			if t.Round.UserState[i] != Out {
-				count++
-				if t.Round.StageBets[i] > max {
-					max = t.Round.StageBets[i]
-				}
-				if i == t.Round.ActorIndex {
-					text += "-> "
-				}
-				text += fmt.Sprintf("[%d] %s", count, t.Players[i].DisplayName)
-				if t.Round.UserState[i] == Fold {
-					text += " FOLD"
-				} else {
-					if t.Round.StageBets[i] > 0 {
-						text += " +"
-					} else {
-						text += " "
-					}
-					text += strconv.FormatInt(t.Round.StageBets[i], 10)
-					text += " / $" + strconv.FormatInt(t.Players[i].Chip, 10)
-					if t.Players[i].Chip <= 0 {
-						text += " *ALL IN*"
-					}
-				}
-				if i == t.Round.Dealer {
-					text += " (Dealer)"
-				}
-				text += "\n"
-			}
-		}
-		text += fmt.Sprintf("Waiting %s (@%s)...",
+		text += t.StatusPlayers()
+		text += trf(lang, "Waiting %s (@%s)...",
 			t.Players[actor].DisplayName, t.Players[actor].Username)
-		if max <= t.Round.StageBets[actor] {
-			buttons = append(buttons, &KeyboardButton{
-				Text: "/check",
-			})
-		}
-		if max > t.Round.StageBets[actor] &&
-			(t.Players[actor].Chip > (max - t.Round.StageBets[actor])) {
-			buttons = append(buttons, &KeyboardButton{
-				Text: "/call",
-			})
-		}
-		if t.Players[actor].Chip > (max - t.Round.StageBets[actor] + 200) {
-			buttons = append(buttons, &KeyboardButton{
-				Text: "/raise",
-			})
-		} else {
-			buttons = append(buttons, &KeyboardButton{
-				Text: "/allin",
-			})
-		}
-		buttons = append(buttons, &KeyboardButton{
-			Text: "/fold",
+		buttons = t.ActionButtons()
+	}
+	return t.sendStatus(text, &InlineKeyboardMarkup{InlineKeyboard: buttons})
+}
+
+// Buttons of the actions the actor can take.
+func (t *Texas) ActionButtons() [][]*InlineKeyboardButton {
+	lang := t.Lang()
+	actor := t.Round.ActorIndex
+	call := t.getMaxBet() - t.Round.StageBets[actor]
+	chip := t.Players[actor].Chip
+	row := make([]*InlineKeyboardButton, 0)
+	if call <= 0 {
+		row = append(row, &InlineKeyboardButton{
+			Text:         tr(lang, "Check"),
+			CallbackData: "check",
+		})
+	} else if chip > call {
+		row = append(row, &InlineKeyboardButton{
+			Text:         trf(lang, "Call %d", call),
+			CallbackData: "call",
 		})
 	}
-	if len(buttons) == 0 {
-		_, err := t.Bot.SendMessage(&SendMessageRequest{
-			ChatID: t.ChatID,
-			Text:   text,
+	row = append(row, &InlineKeyboardButton{
+		Text:         tr(lang, "Fold"),
+		CallbackData: "fold",
+	})
+	buttons := [][]*InlineKeyboardButton{row}
+	raises := make([]*InlineKeyboardButton, 0)
+	for _, option := range t.RaiseOptions() {
+		raises = append(raises, &InlineKeyboardButton{
+			Text:         trf(lang, "%s to %d", tr(lang, option.Name), option.To),
+			CallbackData: "raise:" + strconv.FormatInt(option.To, 10),
 		})
-		return err
-	} else {
-		_, err := t.Bot.SendMessage(&SendMessageRequest{
-			ChatID: t.ChatID,
-			Text:   text,
-			ReplyMarkup: &ReplyKeyboardMarkup{
-				Keyboard:        [][]*KeyboardButton{buttons},
-				ResizeKeyboard:  true,
-				OneTimeKeyboard: true,
-				Selective:       selective,
-			},
+	}
+	for len(raises) > 0 {
+		n := min(int64(len(raises)), 3)
+		buttons = append(buttons, raises[:n])
+		raises = raises[n:]
+	}
+	buttons = append(buttons, []*InlineKeyboardButton{
+		&InlineKeyboardButton{
+			Text:         trf(lang, "All-in to %d", t.Round.StageBets[actor]+chip),
+			CallbackData: "allin",
+		},
+	})
+	return buttons
+}
+
+// Show the text on the table message of the round, or post a new one if the
+// round does not have it yet or it is gone.
+func (t *Texas) sendStatus(text string, markup *InlineKeyboardMarkup) error {
+	if t.Round.StatusMessageID != 0 {
+		_, err := t.Bot.EditMessageText(&EditMessageTextRequest{
+			ChatID:      t.ChatID,
+			MessageID:   t.Round.StatusMessageID,
+			Text:        text,
+			ReplyMarkup: markup,
 		})
+		if err == nil || isNotModified(err) {
+			return nil
+		}
+		if !isMessageGone(err) {
+			return err
+		}
+		log.Println("Error:", err, "< sendStatus")
+	}
+	message, err := t.Bot.SendMessage(&SendMessageRequest{
+		ChatID:      t.ChatID,
+		Text:        text,
+		ReplyMarkup: markup,
+	})
+	if err != nil {
 		return err
 	}
+	t.Round.StatusMessageID = message.MessageID
+	return nil
 }
 
 func (r *Round) NextValidIndex(index int) int {
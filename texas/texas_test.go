@@ -0,0 +1,49 @@
+package texas
+
+import (
+	"testing"
+
+	"github.com/magicae/texas-holdem-bot/telegramtest"
+)
+
+// Show the status of the table again and return the new calls of the bot to
+// the table.
+func showStatusAgain(t *testing.T, table *e2eTable) []*telegramtest.Sent {
+	n := len(server.Sent())
+	err := callTable(table.chat.ID, func() {
+		err := getGame(table.chat.ID).ShowStatus()
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := make([]*telegramtest.Sent, 0)
+	for _, call := range server.Sent()[n:] {
+		if call.ChatID == table.chat.ID {
+			sent = append(sent, call)
+		}
+	}
+	return sent
+}
+
+func TestStatusIsNotPostedAgain(t *testing.T) {
+	table := newE2ETable(t, "Nina", "Omar")
+	table.start("Nina")
+	// The status does not change, so Telegram refuses the edit.
+	if sent := showStatusAgain(t, table); len(sent) != 0 {
+		t.Errorf("Unchanged status is sent with %s", sent[0].Method)
+	}
+	server.DeleteMessage(table.chat.ID, table.status.MessageID)
+	sent := showStatusAgain(t, table)
+	if len(sent) != 1 || sent[0].Method != "sendMessage" {
+		t.Fatalf("Deleted status is not posted again: %v", sent)
+	}
+	if sent := showStatusAgain(t, table); len(sent) != 0 {
+		t.Errorf("Posted status is sent again with %s", sent[0].Method)
+	}
+	table.say("Nina", "/endgame")
+	table.expect(table.chat.ID, "Nina ended the game.")
+	table.checkWallets()
+}
@@ -1,4 +1,4 @@
-package main
+package texas
 
 import (
 	"sort"
@@ -42,13 +42,22 @@ func getUserDisplayName(user *bot.User) string {
 }
 
 func getPokerSticker(card *PokerCard) string {
-	return config.PokerFileIDs[card.Suit][card.Rank]
+	return config.PokerFileID(card.Suit, card.Rank)
 }
 
 func getPokerText(card *PokerCard) string {
 	return config.PokerSuitTexts[card.Suit] + config.PokerRankTexts[card.Rank]
 }
 
+var pokerNotationRanks = [15]string{"", "", "2", "3", "4", "5", "6", "7", "8",
+	"9", "T", "J", "Q", "K", "A"}
+var pokerNotationSuits = [4]string{"d", "h", "c", "s"}
+
+// Get the short notation of a card, e.g. "As" or "Td".
+func getPokerNotation(card *PokerCard) string {
+	return pokerNotationRanks[card.Rank] + pokerNotationSuits[card.Suit]
+}
+
 func min(a int64, b int64) int64 {
 	if a < b {
 		return a
@@ -0,0 +1,109 @@
+package texas
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+
+	. "github.com/magicae/telegram-bot"
+	"github.com/magicae/texas-holdem-bot/config"
+)
+
+// Updates remembered to drop the ones Telegram sends again.
+const webhookRecentUpdates = 1000
+
+// Handlers every update goes through, both by long polling and webhook.
+var handlers = []func(*Bot, *Update) error{metricsHandler,
+	textMessageHandler, callbackQueryHandler}
+
+// recentUpdates remembers the last update IDs.
+type recentUpdates struct {
+	mutex sync.Mutex
+	seen  map[int]bool
+	order []int
+}
+
+// Remember the update ID, false if it has been seen.
+func (r *recentUpdates) Add(id int) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.seen[id] {
+		return false
+	}
+	r.seen[id] = true
+	r.order = append(r.order, id)
+	if len(r.order) > webhookRecentUpdates {
+		delete(r.seen, r.order[0])
+		r.order = r.order[1:]
+	}
+	return true
+}
+
+type webhookHandler struct {
+	Bot     *Bot
+	updates *recentUpdates
+}
+
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	secret := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+	if subtle.ConstantTimeCompare([]byte(secret),
+		[]byte(config.Bot().WebhookSecret)) != 1 {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	update := &Update{}
+	err := json.NewDecoder(r.Body).Decode(update)
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if !h.updates.Add(update.UpdateID) {
+		log.Println("Duplicate update #", update.UpdateID)
+		return
+	}
+	for _, handler := range handlers {
+		err = handler(h.Bot, update)
+		if err != nil {
+			log.Println("Error:", err, "< webhook")
+		}
+	}
+}
+
+// Receive updates by webhook until the bot is stopped, and register the
+// webhook with Telegram meanwhile.
+func runWebhook(e *Bot) {
+	server := &http.Server{
+		Addr: config.Bot().WebhookListen,
+		Handler: &webhookHandler{
+			Bot:     e,
+			updates: &recentUpdates{seen: map[int]bool{}},
+		},
+	}
+	go func() {
+		var err error
+		if config.Bot().WebhookCert != "" {
+			err = server.ListenAndServeTLS(config.Bot().WebhookCert,
+				config.Bot().WebhookKey)
+		} else {
+			err = server.ListenAndServe()
+		}
+		panic("Error: " + err.Error())
+	}()
+	err := setWebhook(config.Bot().WebhookURL, config.Bot().WebhookSecret)
+	if err != nil {
+		panic("Error: " + err.Error())
+	}
+	log.Println("Receiving updates at", config.Bot().WebhookURL)
+	waitForSignal()
+	err = deleteWebhook()
+	if err != nil {
+		log.Println("Error:", err, "< runWebhook")
+	}
+	shutdown(e)
+}
@@ -0,0 +1,154 @@
+package texas
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Just enough of WebSocket (RFC 6455) to push events to web clients.
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Frame opcodes.
+const (
+	websocketText  = 1
+	websocketClose = 8
+	websocketPing  = 9
+	websocketPong  = 10
+)
+
+// Clients only send control frames, which are small.
+const websocketMaxFrame = 4096
+
+const websocketWriteTimeout = 10 * time.Second
+
+var ErrFrameTooLarge = errors.New("WebSocket frame is too large.")
+
+type websocketConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	// Frames are written one at a time.
+	mutex sync.Mutex
+}
+
+// Whether a comma separated header has the token.
+func headerHas(header http.Header, name string, token string) bool {
+	for _, value := range header[name] {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Switch the request to WebSocket. It replies with an error if the request
+// is not a WebSocket handshake.
+func upgradeWebsocket(w http.ResponseWriter, r *http.Request) (
+	*websocketConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || key == "" ||
+		!headerHas(r.Header, "Connection", "upgrade") ||
+		!headerHas(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "WebSocket is required", http.StatusBadRequest)
+		return nil, errors.New("Not a WebSocket handshake.")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket is not supported", http.StatusInternalServerError)
+		return nil, errors.New("Connection can not be hijacked.")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) +
+		"\r\n\r\n")
+	err = rw.Flush()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &websocketConn{conn: conn, reader: rw.Reader}, nil
+}
+
+// Write a frame. Frames of the server are not masked.
+func (c *websocketConn) WriteFrame(opcode byte, payload []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	header := []byte{0x80 | opcode}
+	n := len(payload)
+	switch {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, byte(n>>8), byte(n))
+	default:
+		size := make([]byte, 8)
+		binary.BigEndian.PutUint64(size, uint64(n))
+		header = append(append(header, 127), size...)
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout))
+	_, err := c.conn.Write(append(header, payload...))
+	return err
+}
+
+// Read a frame of the client and unmask it.
+func (c *websocketConn) ReadFrame() (byte, []byte, error) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(c.reader, header)
+	if err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0] & 0x0F
+	n := uint64(header[1] & 0x7F)
+	switch n {
+	case 126:
+		size := make([]byte, 2)
+		_, err = io.ReadFull(c.reader, size)
+		n = uint64(binary.BigEndian.Uint16(size))
+	case 127:
+		size := make([]byte, 8)
+		_, err = io.ReadFull(c.reader, size)
+		n = binary.BigEndian.Uint64(size)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if n > websocketMaxFrame {
+		return 0, nil, ErrFrameTooLarge
+	}
+	mask := make([]byte, 4)
+	if header[1]&0x80 != 0 {
+		_, err = io.ReadFull(c.reader, mask)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, n)
+	_, err = io.ReadFull(c.reader, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
+
+func (c *websocketConn) Close() error {
+	return c.conn.Close()
+}